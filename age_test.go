@@ -0,0 +1,28 @@
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+func TestHumanDuration(t *testing.T) {
+	for _, tc := range []struct {
+		d    time.Duration
+		want string
+	}{
+		{-5 * time.Second, "<invalid>"},
+		{45 * time.Second, "45s"},
+		{90 * time.Second, "90s"},
+		{5*time.Minute + 30*time.Second, "5m30s"},
+		{2 * time.Hour, "120m"},
+		{5*time.Hour + 15*time.Minute, "5h15m"},
+		{30 * time.Hour, "30h"},
+		{3*24*time.Hour + 4*time.Hour, "3d4h"},
+		{10 * 24 * time.Hour, "10d"},
+		{3 * 365 * 24 * time.Hour, "3y"},
+	} {
+		if got := humanDuration(tc.d); got != tc.want {
+			t.Errorf("humanDuration(%s) = %q, want %q", tc.d, got, tc.want)
+		}
+	}
+}
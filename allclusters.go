@@ -0,0 +1,84 @@
+package main
+
+import (
+	"context"
+	"regexp"
+	"strings"
+
+	"golang.org/x/sync/errgroup"
+)
+
+// allClustersFlagRegexp matches the --all-clusters flag that runs a command against every cluster at once
+var allClustersFlagRegexp = regexp.MustCompile(`(^|\s)--all-clusters(\s|$)`)
+
+// extractAllClusters removes an --all-clusters flag from text, returning the remaining text and whether it was there
+func extractAllClusters(text string) (string, bool) {
+	if !allClustersFlagRegexp.MatchString(text) {
+		return text, false
+	}
+
+	return strings.Join(strings.Fields(allClustersFlagRegexp.ReplaceAllString(text, " ")), " "), true
+}
+
+// runAllClusters runs cmd against every cluster concurrently as user, merging the replies into one. Each cluster
+// gets its own API timeout in b.handle, so an unreachable one only costs that long and is reported inline.
+func (b *bot) runAllClusters(ctx context.Context, user string, cmd Command, args map[string]string) string {
+	names := b.clusters.names()
+	replies := make([]string, len(names))
+	errs := make([]error, len(names))
+
+	var g errgroup.Group
+	for i, name := range names {
+		i, name := i, name
+		g.Go(func() error {
+			replies[i], errs[i] = b.run(ctx, user, cmd, name, args)
+			return nil
+		})
+	}
+	g.Wait()
+
+	return mergeClusterReplies(names, replies, errs)
+}
+
+// mergeClusterReplies combines each cluster's reply into one table with a CLUSTER column when they're all tables
+// with the same columns, and otherwise lists them one cluster after another. Failed clusters are listed last.
+func mergeClusterReplies(names, replies []string, errs []error) string {
+	var header []string
+	var rows [][]string
+	var sections, failures []string
+	merge := true
+	for i, name := range names {
+		if errs[i] != nil {
+			failures = append(failures, "⚠️ `"+name+"`: "+errs[i].Error())
+			continue
+		}
+		sections = append(sections, "*"+name+"*\n"+replies[i])
+
+		tableHeader, tableRows, ok := parseTable(replies[i])
+		switch {
+		case !ok:
+			// Clusters with nothing to list just add no rows
+			merge = merge && strings.HasPrefix(replies[i], "No resources found")
+		case header == nil:
+			header = tableHeader
+		case strings.Join(header, "\t") != strings.Join(tableHeader, "\t"):
+			merge = false
+		}
+		for _, row := range tableRows {
+			rows = append(rows, append([]string{name}, row...))
+		}
+	}
+
+	reply := strings.Join(sections, "\n")
+	if merge && header != nil {
+		reply = renderTable(append([]string{"CLUSTER"}, header...), rows)
+	}
+	if len(failures) > 0 {
+		reply = strings.TrimSpace(reply + "\n" + strings.Join(failures, "\n"))
+	}
+	if reply == "" {
+		return "No resources found in any cluster"
+	}
+
+	return reply
+}
@@ -0,0 +1,136 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"os"
+	"sync"
+	"time"
+)
+
+// auditWebhookTimeout is how long posting an entry to AUDIT_WEBHOOK may take
+const auditWebhookTimeout = 5 * time.Second
+
+// The results an audit entry can record
+const (
+	auditSucceeded   = "succeeded"
+	auditFailed      = "failed"
+	auditDenied      = "denied"
+	auditPending     = "pending confirmation"
+	auditStarted     = "started"
+	auditRateLimited = "rate limited"
+)
+
+// auditEntry records one attempt to run a command, whether or not it was allowed to run
+type auditEntry struct {
+	Time      time.Time `json:"time"`
+	User      string    `json:"user"`
+	Channel   string    `json:"channel"`
+	Command   string    `json:"command"`
+	Cluster   string    `json:"cluster,omitempty"`
+	Namespace string    `json:"namespace,omitempty"`
+	Result    string    `json:"result"`
+	Error     string    `json:"error,omitempty"`
+}
+
+// auditor writes audit entries as JSON lines to a file, POSTs them to a webhook, or both. A nil auditor
+// discards everything.
+type auditor struct {
+	mu      sync.Mutex
+	file    *os.File
+	webhook string
+	client  *http.Client
+}
+
+// newAuditor returns an auditor writing to the file at path and posting to webhook, either of which may be
+// empty, or nil if both are
+func newAuditor(path, webhook string) (*auditor, error) {
+	if path == "" && webhook == "" {
+		return nil, nil
+	}
+
+	a := &auditor{webhook: webhook, client: &http.Client{Timeout: auditWebhookTimeout}}
+	if path != "" {
+		file, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o600)
+		if err != nil {
+			return nil, fmt.Errorf("failed to open audit log %q: %w", path, err)
+		}
+		a.file = file
+	}
+
+	return a, nil
+}
+
+// record writes e, logging rather than failing if it can't since the command has happened either way
+func (a *auditor) record(e auditEntry) {
+	if a == nil {
+		return
+	}
+	line, err := json.Marshal(e)
+	if err != nil {
+		slog.Error("failed to marshal audit entry", "err", err)
+		return
+	}
+
+	if a.file != nil {
+		a.mu.Lock()
+		_, err := a.file.Write(append(line, '\n'))
+		a.mu.Unlock()
+		if err != nil {
+			slog.Error("failed to write audit entry", "err", err)
+		}
+	}
+	if a.webhook != "" {
+		// Don't hold up the reply on the webhook
+		go func() {
+			ctx, cancel := context.WithTimeout(context.Background(), auditWebhookTimeout)
+			defer cancel()
+			req, err := http.NewRequestWithContext(ctx, http.MethodPost, a.webhook, bytes.NewReader(line))
+			if err != nil {
+				slog.Error("failed to build audit webhook request", "err", err)
+				return
+			}
+			req.Header.Set("Content-Type", "application/json")
+			resp, err := a.client.Do(req)
+			if err != nil {
+				slog.Error("failed to post audit entry", "err", err)
+				return
+			}
+			resp.Body.Close()
+			if resp.StatusCode >= 300 {
+				slog.Error("audit webhook rejected entry", "status", resp.Status)
+			}
+		}()
+	}
+}
+
+// audit records that m's user tried to run text against kubeContext with args, with result and err
+func (b *bot) audit(m message, text, kubeContext string, args map[string]string, result string, err error) {
+	e := auditEntry{
+		Time:      time.Now().UTC(),
+		User:      m.user,
+		Channel:   m.channel,
+		Command:   text,
+		Cluster:   b.clusters.resolve(kubeContext),
+		Namespace: args["namespace"],
+		Result:    result,
+	}
+	if err != nil {
+		e.Error = err.Error()
+	}
+	b.auditor.record(e)
+}
+
+// finished records the outcome of running cmd in metrics and the audit log
+func (b *bot) finished(m message, cmd Command, text, kubeContext string, args map[string]string, err error) {
+	recordCommand(cmd, m.channel, err)
+	result := auditSucceeded
+	if err != nil {
+		result = auditFailed
+	}
+	b.audit(m, text, kubeContext, args, result, err)
+}
@@ -0,0 +1,130 @@
+package main
+
+import (
+	"context"
+	"os"
+	"path"
+	"strings"
+)
+
+// authorizer restricts which Slack channels and users may run commands, and which namespaces they may run
+// them against. An empty allowlist allows everyone.
+type authorizer struct {
+	channels map[string]bool
+	users    map[string]bool
+	// admins may run commands that change what the whole bot does, e.g. reload-config
+	admins map[string]bool
+
+	// allowedNamespaces and deniedNamespaces are glob patterns, e.g. team-*
+	allowedNamespaces []string
+	deniedNamespaces  []string
+}
+
+// newAuthorizerFromEnv builds an authorizer from the comma-separated ALLOWED_CHANNELS, ALLOWED_USERS, ADMIN_USERS,
+// ALLOWED_NAMESPACES and DENIED_NAMESPACES env vars
+func newAuthorizerFromEnv() authorizer {
+	return authorizer{
+		channels:          stringSet(splitList(os.Getenv("ALLOWED_CHANNELS"))),
+		users:             stringSet(splitList(os.Getenv("ALLOWED_USERS"))),
+		admins:            stringSet(splitList(os.Getenv("ADMIN_USERS"))),
+		allowedNamespaces: splitList(os.Getenv("ALLOWED_NAMESPACES")),
+		deniedNamespaces:  splitList(os.Getenv("DENIED_NAMESPACES")),
+	}
+}
+
+// allowed reports whether user may run commands in channel
+func (a authorizer) allowed(channel, user string) bool {
+	if len(a.channels) > 0 && !a.channels[channel] {
+		return false
+	}
+	if len(a.users) > 0 && !a.users[user] {
+		return false
+	}
+
+	return true
+}
+
+// allowedToMutate reports whether user may run commands that change cluster state. Unlike allowed, this
+// requires ALLOWED_USERS to be set and to contain user.
+func (a authorizer) allowedToMutate(user string) bool {
+	return a.users[user]
+}
+
+// isAdmin reports whether user may run admin commands, which requires ADMIN_USERS to be set and to contain user
+func (a authorizer) isAdmin(user string) bool {
+	return a.admins[user]
+}
+
+// namespaceAllowed reports whether commands may run against namespace. The empty namespace means all
+// namespaces, which is refused whenever any namespace is restricted.
+func (a authorizer) namespaceAllowed(namespace string) bool {
+	if namespace == "" {
+		return len(a.allowedNamespaces) == 0 && len(a.deniedNamespaces) == 0
+	}
+	if matchesAny(a.deniedNamespaces, namespace) {
+		return false
+	}
+
+	return len(a.allowedNamespaces) == 0 || matchesAny(a.allowedNamespaces, namespace)
+}
+
+// namespacePolicy describes the namespaces commands may run against, e.g. for whoami
+func (a authorizer) namespacePolicy() string {
+	if len(a.allowedNamespaces) == 0 && len(a.deniedNamespaces) == 0 {
+		return "all"
+	}
+
+	policy := "all"
+	if len(a.allowedNamespaces) > 0 {
+		policy = strings.Join(a.allowedNamespaces, ",")
+	}
+	if len(a.deniedNamespaces) > 0 {
+		policy += " except " + strings.Join(a.deniedNamespaces, ",")
+	}
+	return policy
+}
+
+type authorizerKey struct{}
+
+// withAuthorizer returns a copy of ctx carrying a for command handlers that filter what they show by namespace
+func withAuthorizer(ctx context.Context, a authorizer) context.Context {
+	return context.WithValue(ctx, authorizerKey{}, a)
+}
+
+// namespaceVisible reports whether the authorizer carried by ctx allows namespace, allowing everything without one
+func namespaceVisible(ctx context.Context, namespace string) bool {
+	a, ok := ctx.Value(authorizerKey{}).(authorizer)
+	return !ok || a.namespaceAllowed(namespace)
+}
+
+// matchesAny reports whether name matches any of the glob patterns
+func matchesAny(patterns []string, name string) bool {
+	for _, pattern := range patterns {
+		if ok, _ := path.Match(pattern, name); ok {
+			return true
+		}
+	}
+
+	return false
+}
+
+// splitList splits a comma-separated list, dropping whitespace and empty entries
+func splitList(s string) []string {
+	var items []string
+	for _, item := range strings.Split(s, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+
+	return items
+}
+
+func stringSet(items []string) map[string]bool {
+	set := make(map[string]bool, len(items))
+	for _, item := range items {
+		set[item] = true
+	}
+
+	return set
+}
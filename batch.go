@@ -0,0 +1,104 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+// getJobsCommand lists Jobs, i.e. kubectl get jobs
+type getJobsCommand struct {
+	regexpCommand
+}
+
+func (getJobsCommand) Handle(ctx context.Context, args map[string]string, clientset kubernetes.Interface) (string, error) {
+	listOptions, err := listOptionsFromArgs(args)
+	if err != nil {
+		return "", err
+	}
+	list, err := clientset.BatchV1().Jobs(args["namespace"]).List(ctx, listOptions)
+	if err != nil {
+		return "", fmt.Errorf("failed to list jobs in `%s`: %w", args["namespace"], err)
+	}
+	if len(list.Items) == 0 {
+		return "No resources found in " + args["namespace"], nil
+	}
+
+	rows := make([][]string, 0, len(list.Items))
+	for _, job := range list.Items {
+		completions := int32(1)
+		if job.Spec.Completions != nil {
+			completions = *job.Spec.Completions
+		}
+		rows = append(rows, []string{job.Name, readyOf(job.Status.Succeeded, completions), jobDuration(job.Status.StartTime, job.Status.CompletionTime), age(job.CreationTimestamp)})
+	}
+
+	return renderSortedTable(args, []string{"NAME", "COMPLETIONS", "DURATION", "AGE"}, rows)
+}
+
+// jobDuration renders how long a job ran for, or has been running for if it hasn't completed yet
+func jobDuration(start, completion *metav1.Time) string {
+	if start == nil {
+		return ""
+	}
+	if completion == nil {
+		return humanDuration(time.Since(start.Time))
+	}
+
+	return humanDuration(completion.Sub(start.Time))
+}
+
+// getCronJobsCommand lists CronJobs, i.e. kubectl get cronjobs
+type getCronJobsCommand struct {
+	regexpCommand
+}
+
+func (getCronJobsCommand) Handle(ctx context.Context, args map[string]string, clientset kubernetes.Interface) (string, error) {
+	listOptions, err := listOptionsFromArgs(args)
+	if err != nil {
+		return "", err
+	}
+
+	// Both versions of CronJob share their shape, but not their Go types
+	var rows [][]string
+	if cl := clusterFrom(ctx); cl != nil && cl.legacy.cronJob {
+		list, err := clientset.BatchV1beta1().CronJobs(args["namespace"]).List(ctx, listOptions)
+		if err != nil {
+			return "", fmt.Errorf("failed to list cronjobs in `%s`: %w", args["namespace"], err)
+		}
+		for _, cj := range list.Items {
+			rows = append(rows, []string{cj.Name, cj.Spec.Schedule, cronJobSuspended(cj.Spec.Suspend), lastSchedule(cj.Status.LastScheduleTime)})
+		}
+	} else {
+		list, err := clientset.BatchV1().CronJobs(args["namespace"]).List(ctx, listOptions)
+		if err != nil {
+			return "", fmt.Errorf("failed to list cronjobs in `%s`: %w", args["namespace"], err)
+		}
+		for _, cj := range list.Items {
+			rows = append(rows, []string{cj.Name, cj.Spec.Schedule, cronJobSuspended(cj.Spec.Suspend), lastSchedule(cj.Status.LastScheduleTime)})
+		}
+	}
+	if len(rows) == 0 {
+		return "No resources found in " + args["namespace"], nil
+	}
+
+	return renderSortedTable(args, []string{"NAME", "SCHEDULE", "SUSPEND", "LAST SCHEDULE"}, rows)
+}
+
+// cronJobSuspended renders a CronJob's optional suspend flag, which defaults to false
+func cronJobSuspended(suspend *bool) string {
+	return strconv.FormatBool(suspend != nil && *suspend)
+}
+
+// lastSchedule renders how long ago a CronJob last created a job, if it ever has
+func lastSchedule(t *metav1.Time) string {
+	if t == nil {
+		return "<none>"
+	}
+
+	return age(*t)
+}
@@ -0,0 +1,232 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strings"
+
+	"github.com/slack-go/slack"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+const (
+	// sectionTextLimit is the most text Slack accepts in a single section block
+	sectionTextLimit = 3000
+	// messageBlockLimit is the most blocks Slack accepts in a single message
+	messageBlockLimit = 50
+	// footerBlocks is how many blocks runBlocks adds after a command's own, the refresh button and usage line
+	footerBlocks = 2
+)
+
+// blocksCommand is implemented by commands with a Block Kit reply that reads better than their plain text one
+type blocksCommand interface {
+	// HandleBlocks runs the command like Handle but returns its reply as Block Kit blocks
+	HandleBlocks(ctx context.Context, args map[string]string, clientset kubernetes.Interface) ([]slack.Block, error)
+}
+
+// runBlocks handles cmd like run, but returns its reply as Block Kit blocks ending with a button to rerun text.
+// Commands without their own blocks have their plain text reply wrapped in a section, and replies too big for a
+// message come back as plain text instead of blocks.
+func (b *bot) runBlocks(ctx context.Context, user string, cmd Command, text, kubeContext string, args map[string]string) ([]slack.Block, string, error) {
+	if kubeContext != "" {
+		text += " --context " + kubeContext
+	}
+
+	blocksCmd, ok := cmd.(blocksCommand)
+	if !ok {
+		reply, err := b.run(ctx, user, cmd, kubeContext, args)
+		if err != nil || len(reply) > sectionTextLimit {
+			return nil, reply, err
+		}
+		return []slack.Block{
+			slack.NewSectionBlock(slack.NewTextBlockObject(slack.MarkdownType, reply, false, false), nil, nil),
+			refreshActions(text),
+			usageContext(cmd),
+		}, reply, nil
+	}
+
+	cl, _, err := b.clusterFor(user, kubeContext)
+	if err != nil {
+		return nil, "", err
+	}
+	blocksCtx := withCluster(ctx, cl)
+	if b.apiTimeout > 0 {
+		var cancel context.CancelFunc
+		blocksCtx, cancel = context.WithTimeout(blocksCtx, b.apiTimeout)
+		defer cancel()
+	}
+	blocks, err := blocksCmd.HandleBlocks(blocksCtx, args, cl.clientset)
+	if err != nil {
+		return nil, "", err
+	}
+	if len(blocks)+footerBlocks > messageBlockLimit {
+		reply, err := b.run(ctx, user, cmd, kubeContext, args)
+		return nil, reply, err
+	}
+
+	return append(blocks, refreshActions(text), usageContext(cmd)), "", nil
+}
+
+// postBlocks sends blocks to m's channel or thread as a single message, with text as the notification fallback
+func (b *bot) postBlocks(m message, blocks []slack.Block, text string) {
+	if m.responseURL != "" {
+		reply := &slack.WebhookMessage{ResponseType: slack.ResponseTypeInChannel, Text: text, Blocks: &slack.Blocks{BlockSet: blocks}}
+		if err := slack.PostWebhook(m.responseURL, reply); err != nil {
+			slog.Error("failed to reply to slash command", "channel", m.channel, "err", err)
+		}
+		return
+	}
+
+	options := []slack.MsgOption{slack.MsgOptionBlocks(blocks...), slack.MsgOptionText(text, false)}
+	if ts := b.replyThread(m); ts != "" {
+		options = append(options, slack.MsgOptionTS(ts))
+	}
+
+	if _, _, err := b.api.PostMessage(m.channel, options...); err != nil {
+		slog.Error("failed to send message", "channel", m.channel, "err", err)
+	}
+}
+
+// refreshActions is an actions block with a button that runs text again
+func refreshActions(text string) *slack.ActionBlock {
+	return slack.NewActionBlock("", slack.NewButtonBlockElement(refreshActionID, text, slack.NewTextBlockObject(slack.PlainTextType, "Refresh", false, false)))
+}
+
+// usageContext is a context block footer naming the command that produced a reply
+func usageContext(cmd Command) *slack.ContextBlock {
+	return slack.NewContextBlock("", slack.NewTextBlockObject(slack.MarkdownType, "mibot · `"+cmd.Usage()+"`", false, false))
+}
+
+// podPhases are the pod phases in the order their groups are shown
+var podPhases = []corev1.PodPhase{corev1.PodRunning, corev1.PodPending, corev1.PodFailed, corev1.PodSucceeded, corev1.PodUnknown}
+
+// HandleBlocks groups pods by phase under a header naming the namespace
+func (getPodCommand) HandleBlocks(ctx context.Context, args map[string]string, clientset kubernetes.Interface) ([]slack.Block, error) {
+	listOptions, err := listOptionsFromArgs(args)
+	if err != nil {
+		return nil, err
+	}
+	pods, err := listPods(ctx, clientset, args["namespace"], listOptions)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list pods in %s: %w", namespaceScope(args["namespace"]), err)
+	}
+	// Grep first, so the problems summary only counts the pods asked about
+	if term := args["grep"]; term != "" && len(pods) > 0 {
+		if pods = grepPods(pods, term); len(pods) == 0 {
+			return []slack.Block{slack.NewSectionBlock(slack.NewTextBlockObject(slack.MarkdownType, noMatches(term), false, false), nil, nil)}, nil
+		}
+	}
+	var healthy string
+	if args["problems"] != "" {
+		pods, healthy = podProblems(pods)
+	}
+
+	title := "Pods in " + args["namespace"]
+	if args["namespace"] == "" {
+		title = "Pods in all namespaces"
+	}
+	blocks := []slack.Block{slack.NewHeaderBlock(slack.NewTextBlockObject(slack.PlainTextType, title, false, false))}
+	if len(pods) == 0 {
+		empty := "No resources found"
+		if healthy != "" {
+			empty = "no problems, " + healthy
+		}
+		return append(blocks, slack.NewSectionBlock(slack.NewTextBlockObject(slack.MarkdownType, empty, false, false), nil, nil)), nil
+	}
+
+	contextFlag := ""
+	if cl := clusterFrom(ctx); cl != nil && cl.name != "" {
+		contextFlag = " --context " + cl.name
+	}
+	byPhase := make(map[corev1.PodPhase][]corev1.Pod)
+	for _, po := range pods {
+		byPhase[po.Status.Phase] = append(byPhase[po.Status.Phase], po)
+	}
+	// Each pod only gets its own section with a Describe and Logs menu if the message can hold them all, along
+	// with the header, a divider and title per phase, the problems summary and runBlocks' footer
+	needed := 1 + 2*len(byPhase) + len(pods) + footerBlocks
+	if healthy != "" {
+		needed += 2
+	}
+	menus := needed <= messageBlockLimit
+	for _, phase := range podPhases {
+		phasePods := byPhase[phase]
+		if len(phasePods) == 0 {
+			continue
+		}
+		blocks = append(blocks, slack.NewDividerBlock())
+		lines := make([]string, 0, len(phasePods))
+		for _, po := range phasePods {
+			name := po.Name
+			if args["allNamespaces"] != "" {
+				name = po.Namespace + "/" + po.Name
+			}
+			ready, restarts := podReadiness(po)
+			line := fmt.Sprintf("`%s` %s ready, %d restarts, %s old", name, ready, restarts, age(po.CreationTimestamp))
+			if args["wide"] != "" {
+				line = fmt.Sprintf("%s, %s on %s", line, orNone(po.Status.PodIP), orNone(po.Spec.NodeName))
+			}
+			if status := podStatus(po); status != string(phase) {
+				line = fmt.Sprintf("%s *%s*", line, status)
+				if !statusEmoji {
+					line = "⚠️ " + line
+				}
+			}
+			if statusEmoji {
+				line = podEmoji(po) + " " + line
+			}
+			lines = append(lines, line)
+		}
+
+		title := fmt.Sprintf("*%s* (%d)", phase, len(phasePods))
+		if menus {
+			blocks = append(blocks, slack.NewSectionBlock(slack.NewTextBlockObject(slack.MarkdownType, title, false, false), nil, nil))
+			for i, po := range phasePods {
+				blocks = append(blocks, slack.NewSectionBlock(slack.NewTextBlockObject(slack.MarkdownType, lines[i], false, false), nil, slack.NewAccessory(podActions(po, contextFlag))))
+			}
+			continue
+		}
+		for i, section := range splitSections(title, lines) {
+			if i > 0 {
+				section = "…\n" + section
+			}
+			blocks = append(blocks, slack.NewSectionBlock(slack.NewTextBlockObject(slack.MarkdownType, section, false, false), nil, nil))
+		}
+	}
+
+	if healthy != "" {
+		blocks = append(blocks, slack.NewDividerBlock(), slack.NewSectionBlock(slack.NewTextBlockObject(slack.MarkdownType, healthy, false, false), nil, nil))
+	}
+
+	return blocks, nil
+}
+
+// podActions is an overflow menu that describes po or shows its logs
+func podActions(po corev1.Pod, contextFlag string) *slack.OverflowBlockElement {
+	target := po.Name + " -n " + po.Namespace + contextFlag
+	return slack.NewOverflowBlockElement(podActionID,
+		slack.NewOptionBlockObject(commandPrefixes[0]+" describe po "+target, slack.NewTextBlockObject(slack.PlainTextType, "Describe", false, false), nil),
+		slack.NewOptionBlockObject(commandPrefixes[0]+" logs "+target, slack.NewTextBlockObject(slack.PlainTextType, "Logs", false, false), nil),
+	)
+}
+
+// splitSections joins title and lines into as few section texts as fit within sectionTextLimit, title first
+func splitSections(title string, lines []string) []string {
+	var sections []string
+	var section strings.Builder
+	section.WriteString(title)
+	for _, line := range lines {
+		if section.Len()+len(line)+1 > sectionTextLimit-len("…\n") {
+			sections = append(sections, section.String())
+			section.Reset()
+		}
+		if section.Len() > 0 {
+			section.WriteString("\n")
+		}
+		section.WriteString(line)
+	}
+
+	return append(sections, section.String())
+}
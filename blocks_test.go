@@ -0,0 +1,80 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/slack-go/slack"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+// podsInPhases returns n pods in namespace team-a, spread across every phase in turn
+func podsInPhases(n int, name string) []runtime.Object {
+	pods := make([]runtime.Object, 0, n)
+	for i := 0; i < n; i++ {
+		pods = append(pods, &corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Name: fmt.Sprintf("%s-%d", name, i), Namespace: "team-a"},
+			Status:     corev1.PodStatus{Phase: podPhases[i%len(podPhases)]},
+		})
+	}
+	return pods
+}
+
+func TestRunBlocksFitsMessageBlockLimit(t *testing.T) {
+	for _, tc := range []struct {
+		name  string
+		pods  int
+		menus bool
+	}{
+		{"few pods get menus", 10, true},
+		{"40 pods across 5 phases", 40, false},
+		{"35 pods across 5 phases", 35, true},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			cl := &cluster{clientset: fake.NewSimpleClientset(podsInPhases(tc.pods, "web")...), breaker: &breaker{}}
+			b := &bot{clusters: &clusters{byName: map[string]*cluster{"": cl}}, cache: newReplyCache(0)}
+			args := map[string]string{"namespace": "team-a"}
+
+			blocks, reply, err := b.runBlocks(context.Background(), "U1", getPodCommand{}, "k get po -n team-a", "", args)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if blocks == nil {
+				t.Fatalf("got plain text %q, want blocks", reply)
+			}
+			if len(blocks) > messageBlockLimit {
+				t.Errorf("got %d blocks, over Slack's limit of %d", len(blocks), messageBlockLimit)
+			}
+			menus := false
+			for _, block := range blocks {
+				if section, ok := block.(*slack.SectionBlock); ok && section.Accessory != nil {
+					menus = true
+				}
+			}
+			if menus != tc.menus {
+				t.Errorf("pod menus = %t, want %t", menus, tc.menus)
+			}
+		})
+	}
+}
+
+func TestRunBlocksFallsBackToText(t *testing.T) {
+	cl := &cluster{clientset: fake.NewSimpleClientset(podsInPhases(3000, "web-"+strings.Repeat("x", 40))...), breaker: &breaker{}}
+	b := &bot{clusters: &clusters{byName: map[string]*cluster{"": cl}}, cache: newReplyCache(0)}
+
+	blocks, reply, err := b.runBlocks(context.Background(), "U1", getPodCommand{}, "k get po -n team-a", "", map[string]string{"namespace": "team-a"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if blocks != nil {
+		t.Errorf("got %d blocks, want the plain text reply", len(blocks))
+	}
+	if !strings.Contains(reply, "web-"+strings.Repeat("x", 40)+"-2999") {
+		t.Error("plain text reply is missing pods")
+	}
+}
@@ -0,0 +1,582 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/slack-go/slack"
+	"k8s.io/client-go/kubernetes"
+)
+
+// snippetThreshold is the reply length above which replies are uploaded as a snippet rather than posted inline
+const snippetThreshold = 3000
+
+// messageLimit is roughly how long a message can be before Slack starts truncating it
+const messageLimit = 4000
+
+// message is a Slack message seen by the bot, independent of the transport it arrived on
+type message struct {
+	channel         string
+	user            string
+	text            string
+	timestamp       string
+	threadTimestamp string
+	// responseURL is where replies to a slash command go, since mibot may not be in the channel it was run in
+	responseURL string
+}
+
+// bot dispatches Slack messages to commands and posts the replies, shared by every transport
+type bot struct {
+	api      *slack.Client
+	userID   string
+	clusters *clusters
+	auth     authorizer
+	cache    *replyCache
+	auditor  *auditor
+
+	// apiTimeout bounds how long a command may wait on the Kubernetes API, 0 means no limit
+	apiTimeout time.Duration
+
+	// rateLimiter caps how often each user may run commands
+	rateLimiter *userRateLimiter
+	// impersonation maps Slack users to the Kubernetes users their commands run as
+	impersonation impersonation
+	// confirmations holds destructive commands waiting for their user to confirm them
+	confirmations *confirmations
+	// streams holds the streamed replies still being updated
+	streams *streams
+	// seen holds the messages handled lately, so redelivered ones are ignored
+	seen *recentMessages
+
+	// threadReplies posts replies in a thread on the triggering message rather than in the channel
+	threadReplies bool
+	// paginate splits large replies over several messages rather than uploading them as a snippet
+	paginate bool
+	// blocks replies with Block Kit blocks rather than plain text where it can
+	blocks bool
+	// unknownReply is the reply to messages that aren't commands
+	unknownReply string
+	// quietOnUnknown ignores messages that aren't commands rather than replying with unknownReply
+	quietOnUnknown bool
+	// readOnly refuses every mutating command, whoever asks
+	readOnly bool
+	// defaultNamespace is the namespace of commands that leave out -n, empty to make them ask for one
+	defaultNamespace string
+	// streamDuration and streamInterval are how long streamed replies keep updating and how often
+	streamDuration time.Duration
+	streamInterval time.Duration
+
+	// workers are the queues of the workers handling messages, see startWorkers
+	workers []chan message
+
+	// ready is set once the bot is connected to Slack and able to serve commands
+	ready *atomic.Bool
+	// latency is the latest round trip to Slack in nanoseconds, when the transport reports it
+	latency *atomic.Int64
+}
+
+// handleMessage runs the command in m if it is addressed to the bot and replies in the same channel
+func (b *bot) handleMessage(ctx context.Context, m message) {
+	text, ok := b.addressedText(m)
+	if !ok {
+		return
+	}
+	// Casual mentions of the bot shouldn't get a reply, or even a reaction, when we're asked to be quiet
+	if b.quietOnUnknown && unknown(text) {
+		slog.Info("ignoring unknown command", "user", m.user, "channel", m.channel, "text", text)
+		return
+	}
+	if refusal := b.refusal(m); refusal != "" {
+		b.send(m, refusal)
+		return
+	}
+	slog.Info("command received", "user", m.user, "channel", m.channel, "text", text)
+
+	// Acknowledge the message straight away, since some commands take a while to reply
+	b.react(m, "eyes")
+	reply, err := b.respond(ctx, m, text)
+	b.unreact(m, "eyes")
+	if err != nil {
+		b.react(m, "x")
+		b.reportError(m, err)
+		return
+	}
+	b.react(m, "white_check_mark")
+	// Streams, blocks and snippets post their own replies
+	if reply == "" {
+		return
+	}
+	slog.Debug("sending reply", "channel", m.channel, "reply", reply)
+	b.send(m, reply)
+}
+
+// react adds the emoji reaction name to m, logging rather than failing since reactions are only a courtesy
+func (b *bot) react(m message, name string) {
+	if err := b.api.AddReaction(name, slack.NewRefToMessage(m.channel, m.timestamp)); err != nil {
+		slog.Warn("failed to add reaction", "channel", m.channel, "reaction", name, "err", err)
+	}
+}
+
+// unreact removes the emoji reaction name from m
+func (b *bot) unreact(m message, name string) {
+	if err := b.api.RemoveReaction(name, slack.NewRefToMessage(m.channel, m.timestamp)); err != nil {
+		slog.Warn("failed to remove reaction", "channel", m.channel, "reaction", name, "err", err)
+	}
+}
+
+// refusal returns why m's user may not run commands in m's channel right now, or "" if they may
+func (b *bot) refusal(m message) string {
+	if !b.auth.allowed(m.channel, m.user) {
+		slog.Warn("ignoring command from unauthorized user", "user", m.user, "channel", m.channel)
+		b.audit(m, m.text, "", nil, auditDenied, nil)
+		return "you are not authorized."
+	}
+	if !b.rateLimiter.allow(m.user) {
+		slog.Warn("dropping command from rate limited user", "user", m.user, "channel", m.channel)
+		b.audit(m, m.text, "", nil, auditRateLimited, nil)
+		return "slow down, you're rate limited"
+	}
+
+	return ""
+}
+
+// addressedText returns the text of m with the bot mention stripped, and whether m is addressed to the bot at
+// all. Messages in channels must mention the bot, while every message in a direct message is for it.
+func (b *bot) addressedText(m message) (string, bool) {
+	// Our own replies show up as messages too, and answering them would loop forever in a DM
+	if m.user == b.userID {
+		return "", false
+	}
+
+	botTagString := fmt.Sprintf("<@%s>", b.userID)
+	if !strings.Contains(m.text, botTagString) && !isDirectMessage(m.channel) {
+		return "", false
+	}
+
+	// The mention can go anywhere, so remove it wherever it is and close up the gap it leaves
+	return strings.Join(strings.Fields(strings.ReplaceAll(m.text, botTagString, " ")), " "), true
+}
+
+// isDirectMessage reports whether channel is a direct message with the bot, which Slack gives IDs starting with D
+func isDirectMessage(channel string) bool {
+	return strings.HasPrefix(channel, "D")
+}
+
+// send replies to m with text, logging rather than failing if Slack rejects it. Replies too big to read
+// comfortably inline are uploaded as a snippet instead.
+func (b *bot) send(m message, text string) {
+	if len(text) > snippetThreshold {
+		if b.paginate {
+			for _, page := range paginate(text, messageLimit) {
+				b.post(m, page)
+			}
+		} else {
+			b.upload(m, text, "mibot.txt", "text")
+		}
+		return
+	}
+
+	b.post(m, text)
+}
+
+// post sends text to m's channel or thread as a single message, returning its timestamp so it can be edited
+func (b *bot) post(m message, text string) (string, error) {
+	options := []slack.MsgOption{slack.MsgOptionText(text, false)}
+	if ts := b.replyThread(m); ts != "" {
+		options = append(options, slack.MsgOptionTS(ts))
+	}
+
+	_, ts, err := b.api.PostMessage(m.channel, options...)
+	if err != nil {
+		slog.Error("failed to send message", "channel", m.channel, "err", err)
+	}
+	return ts, err
+}
+
+// paginate splits text into code blocks of at most limit characters, breaking only between lines. A single
+// line longer than limit gets a page to itself.
+func paginate(text string, limit int) []string {
+	const fence = "```\n"
+	limit -= len(fence) + len("```")
+
+	var pages []string
+	var page strings.Builder
+	for _, line := range strings.Split(strings.Trim(strings.ReplaceAll(text, "```", ""), "\n"), "\n") {
+		if page.Len() > 0 && page.Len()+len(line)+1 > limit {
+			pages = append(pages, fence+page.String()+"```")
+			page.Reset()
+		}
+		page.WriteString(line + "\n")
+	}
+	if page.Len() > 0 {
+		pages = append(pages, fence+page.String()+"```")
+	}
+
+	return pages
+}
+
+// upload replies to m with text as a file snippet called filename, highlighted as filetype
+func (b *bot) upload(m message, text, filename, filetype string) {
+	_, err := b.api.UploadFile(slack.FileUploadParameters{
+		Content:         strings.ReplaceAll(text, "```", ""),
+		Filetype:        filetype,
+		Filename:        filename,
+		Channels:        []string{m.channel},
+		ThreadTimestamp: b.replyThread(m),
+	})
+	if err != nil {
+		slog.Error("failed to upload snippet", "channel", m.channel, "err", err)
+	}
+}
+
+// replyThread returns the thread timestamp replies to m belong in, or "" to reply in the channel
+func (b *bot) replyThread(m message) string {
+	if !b.threadReplies {
+		return ""
+	}
+	if m.threadTimestamp != "" {
+		return m.threadTimestamp
+	}
+
+	return m.timestamp
+}
+
+// reportError logs err and tells the user what went wrong without taking the bot down
+func (b *bot) reportError(m message, err error) {
+	slog.Error("command failed", "user", m.user, "channel", m.channel, "err", err)
+	b.send(m, fmt.Sprintf("⚠️ %v", err))
+}
+
+// uncachedCommand is implemented by read-only commands whose replies go stale too quickly to cache
+type uncachedCommand interface {
+	Uncached()
+}
+
+// snippetCommand is implemented by commands whose replies are always uploaded as a file snippet, since they're
+// too big to read inline
+type snippetCommand interface {
+	// Snippet returns the filename and Slack filetype of the snippet for the reply to args
+	Snippet(args map[string]string) (string, string)
+}
+
+// respond works out the reply to m, whose text has had the bot mention stripped, independent of how it reached us
+func (b *bot) respond(ctx context.Context, m message, text string) (string, error) {
+	ctx = withLatency(ctx, b.latency)
+	ctx = withClusters(ctx, b.clusters)
+	ctx = withMessageText(ctx, m.text)
+	ctx = withAuthorizer(ctx, b.auth)
+	if text == "confirm" {
+		action, ok, err := b.confirmations.take(m.user, m.channel)
+		if err != nil {
+			return "", err
+		}
+		if !ok {
+			return "there's nothing waiting for you to confirm, it may have expired", nil
+		}
+		cmd := action.command()
+		// The action may have been queued by another replica, or before the user or bot lost the right to run it
+		if refusal := b.forbidden(m, cmd, action.Text, action.KubeContext, action.Args); refusal != "" {
+			return refusal, nil
+		}
+		reply, err := b.run(ctx, m.user, cmd, action.KubeContext, action.Args)
+		b.finished(m, cmd, action.Text, action.KubeContext, action.Args, err)
+		return reply, err
+	}
+
+	if text == "stop" {
+		if !b.streams.stop(m.user, m.channel) {
+			return "there's nothing streaming for you to stop", nil
+		}
+		return "stopped", nil
+	}
+
+	text, kubeContext := extractContext(text)
+	text, allClusters := extractAllClusters(text)
+	text, watch := extractWatch(text)
+	cmd := findCommand(text)
+	if cmd == nil {
+		return b.fallbackReply(text), nil
+	}
+	_, admin := cmd.(adminCommand)
+	if allClusters && (cmd.Mutating() || admin || watch || kubeContext != "") {
+		return "`--all-clusters` only works with read-only commands, without `watch` or `--context`", nil
+	}
+	if watch {
+		// Admin commands change the bot itself, e.g. reload-config, so rerunning them every interval would too
+		if cmd.Mutating() || admin {
+			return "only read-only commands can be watched", nil
+		}
+		cmd = watchCommand{Command: cmd, interval: b.streamInterval, refresh: b.refresh}
+	}
+
+	args := cmd.Args(text)
+	if !b.fillNamespace(cmd, args) {
+		return "which namespace? add `-n $namespace` to the command", nil
+	}
+	if refusal := b.forbidden(m, cmd, text, kubeContext, args); refusal != "" {
+		return refusal, nil
+	}
+
+	if allClusters {
+		if streaming, ok := cmd.(streamingCommand); ok && streaming.Streams(args) {
+			return "streamed commands can't run with `--all-clusters`", nil
+		}
+		reply := b.runAllClusters(ctx, m.user, cmd, args)
+		b.finished(m, cmd, text+" --all-clusters", kubeContext, args, nil)
+		return reply, nil
+	}
+
+	// Progress edits its reply in place, which a slash command's response URL can't do
+	if _, ok := cmd.(progressCommand); ok && !b.blocks && m.responseURL == "" {
+		reply, err := b.runWithProgress(ctx, m, cmd, kubeContext, args)
+		b.finished(m, cmd, text, kubeContext, args, err)
+		return reply, err
+	}
+
+	if confirmed, ok := cmd.(confirmedCommand); ok {
+		if err := b.confirmations.add(m.user, m.channel, pendingAction{Text: text, KubeContext: kubeContext, Args: args}); err != nil {
+			return "", err
+		}
+		b.audit(m, text, kubeContext, args, auditPending, nil)
+		if m.responseURL != "" {
+			return fmt.Sprintf("run the slash command again with `confirm` within %s to %s", confirmationWindow, confirmed.ConfirmationPrompt(args)), nil
+		}
+		return fmt.Sprintf("reply `confirm` within %s to %s", confirmationWindow, confirmed.ConfirmationPrompt(args)), nil
+	}
+
+	if streaming, ok := cmd.(streamingCommand); ok && streaming.Streams(args) {
+		if m.responseURL != "" {
+			return "streamed commands can't run as a slash command, mention mibot in a channel it's in instead", nil
+		}
+		// The stream records its own outcome in metrics once it ends
+		b.audit(m, text, kubeContext, args, auditStarted, nil)
+		return "", b.stream(ctx, m, cmd, kubeContext, args)
+	}
+
+	if snippet, ok := cmd.(snippetCommand); ok {
+		reply, err := b.run(ctx, m.user, cmd, kubeContext, args)
+		b.finished(m, cmd, text, kubeContext, args, err)
+		if err != nil {
+			return "", err
+		}
+		// Snippets are uploaded to the channel, so a slash command gets the contents inline instead
+		if m.responseURL != "" {
+			return "```\n" + reply + "```", nil
+		}
+		filename, filetype := snippet.Snippet(args)
+		b.upload(m, reply, filename, filetype)
+		return "", nil
+	}
+	if b.blocks {
+		blocks, reply, err := b.runBlocks(ctx, m.user, cmd, text, kubeContext, args)
+		b.finished(m, cmd, text, kubeContext, args, err)
+		if err != nil || blocks == nil {
+			return reply, err
+		}
+		b.postBlocks(m, blocks, "mibot: "+cmd.Usage())
+		return "", nil
+	}
+
+	reply, err := b.run(ctx, m.user, cmd, kubeContext, args)
+	b.finished(m, cmd, text, kubeContext, args, err)
+	return reply, err
+}
+
+// run handles cmd for user against the cluster for kubeContext, serving read-only commands from the cache when
+// possible. Commands run as the bot unless user has a Kubernetes user to impersonate.
+func (b *bot) run(ctx context.Context, user string, cmd Command, kubeContext string, args map[string]string) (string, error) {
+	cl, username, err := b.clusterFor(user, kubeContext)
+	if err != nil {
+		return "", err
+	}
+	// The cache is shared, so impersonated users each get their own entries
+	cacheKey := fmt.Sprintf("%T|%s|%v|%s", cmd, kubeContext, args, username)
+	ctx = withCluster(ctx, cl)
+	clientset := cl.clientset
+	if _, ok := cmd.(botCommand); !ok {
+		if err := b.checkBreaker(ctx, cl); err != nil {
+			return "", err
+		}
+	}
+
+	// Commands managing the bot may change which clusters there are, e.g. reload-config, so cached replies are
+	// stale after them just as they are after a mutation
+	if _, managesBot := cmd.(botCommand); cmd.Mutating() || managesBot {
+		reply, err := b.handle(ctx, cmd, args, clientset)
+		if err == nil {
+			b.cache.clear()
+		}
+		return reply, err
+	}
+	if _, ok := cmd.(uncachedCommand); ok {
+		return b.handle(ctx, cmd, args, clientset)
+	}
+
+	if reply, ok := b.cache.get(cacheKey); ok {
+		slog.Debug("cache hit", "key", cacheKey)
+		return reply, nil
+	}
+	slog.Debug("cache miss", "key", cacheKey)
+	reply, err := b.handle(ctx, cmd, args, clientset)
+	if err != nil {
+		return "", err
+	}
+	b.cache.set(cacheKey, reply)
+	return reply, nil
+}
+
+// clusterFor returns the cluster for kubeContext as user should see it, along with the Kubernetes user it
+// impersonates for them or "" if commands run as the bot
+func (b *bot) clusterFor(user, kubeContext string) (*cluster, string, error) {
+	cl, err := b.clusters.get(kubeContext)
+	if err != nil {
+		return nil, "", err
+	}
+	username, ok := b.impersonation[user]
+	if !ok {
+		return cl, "", nil
+	}
+	if cl, err = cl.impersonate(username); err != nil {
+		return nil, "", err
+	}
+
+	return cl, username, nil
+}
+
+// handle runs cmd, giving up once it has waited apiTimeout on the Kubernetes API so a hung API server can't
+// hang the command with it
+func (b *bot) handle(ctx context.Context, cmd Command, args map[string]string, clientset kubernetes.Interface) (string, error) {
+	parent := ctx
+	if b.apiTimeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, b.apiTimeout)
+		defer cancel()
+	}
+
+	var reply string
+	run := func() (err error) {
+		reply, err = cmd.Handle(ctx, args, clientset)
+		return err
+	}
+	// Mutations aren't retried since a reset connection doesn't say whether the change was made, and neither are
+	// commands managing the bot, whose failures aren't the cluster's
+	_, managesBot := cmd.(botCommand)
+	var err error
+	if cmd.Mutating() || managesBot {
+		err = run()
+	} else {
+		err = withRetry(ctx, commandName(cmd), run)
+	}
+	// A caller giving up, e.g. a watch running out of time part way through a refresh, says nothing about the cluster
+	if parent.Err() != nil {
+		return "", err
+	}
+	timedOut := err != nil && errors.Is(ctx.Err(), context.DeadlineExceeded)
+	if cl := clusterFrom(ctx); cl != nil && cl.breaker != nil && !managesBot {
+		cl.breaker.record(cl.name, timedOut || err != nil && unreachable(err))
+	}
+	if timedOut {
+		return "", fmt.Errorf("timed out after %s waiting for the Kubernetes API: %w", b.apiTimeout, err)
+	}
+	return reply, err
+}
+
+// forbidden returns why m's user may not run cmd with args right now, auditing the refusal, or "" if they may
+func (b *bot) forbidden(m message, cmd Command, text, kubeContext string, args map[string]string) string {
+	if cmd.Mutating() && b.readOnly {
+		slog.Warn("refusing mutating command in read-only mode", "user", m.user)
+		b.audit(m, text, kubeContext, args, auditDenied, nil)
+		return "mibot is running in read-only mode"
+	}
+	if cmd.Mutating() && !b.auth.allowedToMutate(m.user) {
+		slog.Warn("refusing mutating command from user not in ALLOWED_USERS", "user", m.user)
+		b.audit(m, text, kubeContext, args, auditDenied, nil)
+		return "you are not authorized to change cluster state."
+	}
+	if _, ok := cmd.(adminCommand); ok && !b.auth.isAdmin(m.user) {
+		slog.Warn("refusing admin command from user not in ADMIN_USERS", "user", m.user)
+		b.audit(m, text, kubeContext, args, auditDenied, nil)
+		return "only mibot admins can do that."
+	}
+	// Without -n or -A, fillNamespace only leaves the namespace empty for commands that may resolve to a
+	// cluster-scoped resource, and those refuse namespaced ones themselves, so there's no namespace to check yet
+	namespace, ok := args["namespace"]
+	if ok && (namespace != "" || args["allNamespaces"] != "") && !b.auth.namespaceAllowed(namespace) {
+		slog.Warn("refusing command against restricted namespace", "user", m.user, "namespace", namespace)
+		b.audit(m, text, kubeContext, args, auditDenied, nil)
+		if namespace == "" {
+			return "listing across all namespaces is not accessible via mibot"
+		}
+		return fmt.Sprintf("namespace `%s` is not accessible via mibot", namespace)
+	}
+
+	return ""
+}
+
+// fillNamespace sets the namespace in args to defaultNamespace when the command takes one but -n was left out,
+// reporting false if there's no default to use
+func (b *bot) fillNamespace(cmd Command, args map[string]string) bool {
+	if namespace, ok := args["namespace"]; !ok || namespace != "" || args["allNamespaces"] != "" {
+		return true
+	}
+	if b.defaultNamespace != "" {
+		args["namespace"] = b.defaultNamespace
+		return true
+	}
+
+	// Cluster-scoped resources need no namespace, and these commands ask for one themselves when they do
+	if w, ok := cmd.(watchCommand); ok {
+		cmd = w.Command
+	}
+	switch cmd.(type) {
+	case getOutputCommand, getResourceCommand:
+		return true
+	}
+	return false
+}
+
+// defaultUnknownReply is the reply to messages that aren't commands unless UNKNOWN_REPLY says otherwise
+const defaultUnknownReply = "I'm mibot. I'm alive, but idk what you want from me! Try help? :narwhal-dancing:"
+
+// fallbackReply is the reply to text that isn't a command
+func (b *bot) fallbackReply(text string) string {
+	if strings.Contains(text, "help") {
+		return helpText()
+	}
+	if suggestion := suggestCommand(text); suggestion != "" {
+		return fmt.Sprintf("did you mean `%s`?", suggestion)
+	}
+
+	return b.unknownReply
+}
+
+// unknown reports whether text is neither a command nor anything else fallbackReply has a useful answer to
+func unknown(text string) bool {
+	if text == "confirm" || text == "stop" || strings.Contains(text, "help") {
+		return false
+	}
+	text, _ = extractContext(text)
+	text, _ = extractWatch(text)
+
+	return findCommand(text) == nil && suggestCommand(text) == ""
+}
+
+// helpText lists every registered command so help never goes stale
+func helpText() string {
+	rows := make([][]string, 0, len(commands))
+	for _, cmd := range commands {
+		rows = append(rows, []string{cmd.Usage(), cmd.Description()})
+	}
+
+	return renderTable([]string{"COMMAND", "DESCRIPTION"}, rows) + "\n" +
+		"get commands accept `-l $selector` and `--field-selector $selector` to filter, `grep $term` to keep names containing $term, and `--sort-by=$key` to sort. " +
+		"get pods also accepts `--problems` to only list unhealthy pods and `-o wide` for their IP and node, as does get deploy for its images. " +
+		"Any command accepts `--context $context` to pick a cluster, and read-only ones `--all-clusters` to ask every cluster at once. " +
+		"Prefix a read-only command with `watch` to keep its reply up to date. " +
+		"Reply `confirm` when asked to go ahead with a destructive command, or `stop` to end a streamed reply early."
+}
@@ -0,0 +1,171 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"sync/atomic"
+	"syscall"
+	"testing"
+	"time"
+
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func TestConfirmRechecksPolicy(t *testing.T) {
+	b := &bot{
+		clusters:      &clusters{byName: map[string]*cluster{}},
+		confirmations: newConfirmations(newMemoryStore()),
+		auth:          authorizer{users: map[string]bool{"U1": true}},
+		readOnly:      true,
+		latency:       new(atomic.Int64),
+	}
+	// Queued by a replica that wasn't read-only
+	action := pendingAction{Text: "k delete po web -n team-a", Args: map[string]string{"pod": "web", "namespace": "team-a"}}
+	if err := b.confirmations.add("U1", "C1", action); err != nil {
+		t.Fatal(err)
+	}
+
+	reply, err := b.respond(context.Background(), message{channel: "C1", user: "U1", text: "confirm"}, "confirm")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if reply != "mibot is running in read-only mode" {
+		t.Errorf("confirm replied %q, want the read-only refusal", reply)
+	}
+}
+
+func TestPaginateKeepsLinesWhole(t *testing.T) {
+	var lines []string
+	for i := 0; i < 30; i++ {
+		lines = append(lines, fmt.Sprintf("line-%02d %s", i, strings.Repeat("x", 30)))
+	}
+
+	pages := paginate("```\n"+strings.Join(lines, "\n")+"\n```", 500)
+	if len(pages) != 3 {
+		t.Fatalf("got %d pages, want 3", len(pages))
+	}
+	var got []string
+	for _, page := range pages {
+		if len(page) > 500 {
+			t.Errorf("page is %d long, over the 500 limit", len(page))
+		}
+		if !strings.HasPrefix(page, "```\n") || !strings.HasSuffix(page, "\n```") {
+			t.Errorf("page %q isn't a whole code block", page)
+		}
+		got = append(got, strings.Split(strings.TrimSuffix(strings.TrimPrefix(page, "```\n"), "\n```"), "\n")...)
+	}
+	if !reflect.DeepEqual(got, lines) {
+		t.Errorf("pages split or lost lines: %q", got)
+	}
+}
+
+func TestAddressedText(t *testing.T) {
+	b := &bot{userID: "UBOT"}
+	for _, tc := range []struct {
+		name      string
+		m         message
+		want      string
+		addressed bool
+	}{
+		{"channel without mention", message{channel: "C1", user: "U1", text: "k get po"}, "", false},
+		{"channel with mention", message{channel: "C1", user: "U1", text: "<@UBOT> k get po"}, "k get po", true},
+		{"direct message", message{channel: "D1", user: "U1", text: "k get po"}, "k get po", true},
+		{"mention mid-text", message{channel: "C1", user: "U1", text: "k get <@UBOT>  po -n foo"}, "k get po -n foo", true},
+		{"own reply", message{channel: "D1", user: "UBOT", text: "k get po"}, "", false},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			got, addressed := b.addressedText(tc.m)
+			if got != tc.want || addressed != tc.addressed {
+				t.Errorf("addressedText(%q) = %q, %t, want %q, %t", tc.m.text, got, addressed, tc.want, tc.addressed)
+			}
+		})
+	}
+}
+
+func TestForbiddenAllowsClusterScopedUnderNamespacePolicy(t *testing.T) {
+	b := &bot{
+		clusters: &clusters{byName: map[string]*cluster{}},
+		auth:     authorizer{allowedNamespaces: []string{"team-a"}},
+	}
+	m := message{channel: "C1", user: "U1"}
+
+	for text, want := range map[string]string{
+		"k get crd":              "",
+		"k get clusterroles":     "",
+		"k get node foo -o yaml": "",
+		"k get po foo -o yaml":   "",
+		"k get crd -A":           "listing across all namespaces is not accessible via mibot",
+		"k get po -n team-a":     "",
+		"k get po -n team-b":     "namespace `team-b` is not accessible via mibot",
+	} {
+		cmd := findCommand(text)
+		if cmd == nil {
+			t.Fatalf("%s matched no command", text)
+		}
+		args := cmd.Args(text)
+		if !b.fillNamespace(cmd, args) {
+			t.Fatalf("%s asked for a namespace", text)
+		}
+		if got := b.forbidden(m, cmd, text, "", args); got != want {
+			t.Errorf("forbidden(%s) = %q, want %q", text, got, want)
+		}
+	}
+}
+
+// flakyBotCommand is a command managing the bot that fails the way an unreachable cluster does, counting its runs
+type flakyBotCommand struct {
+	regexpCommand
+	runs *int
+}
+
+func (c flakyBotCommand) Handle(context.Context, map[string]string, kubernetes.Interface) (string, error) {
+	*c.runs++
+	return "", syscall.ECONNREFUSED
+}
+
+func (flakyBotCommand) ManagesBot() {}
+
+func (flakyBotCommand) Uncached() {}
+
+func TestRunSkipsBreakerAndRetriesForBotCommands(t *testing.T) {
+	br := &breaker{failures: breakerThreshold, openUntil: time.Now().Add(breakerCooldown)}
+	b := &bot{clusters: &clusters{byName: map[string]*cluster{"": {clientset: fake.NewSimpleClientset(), breaker: br}}}}
+
+	runs := 0
+	cmd := flakyBotCommand{runs: &runs}
+	if _, err := b.run(context.Background(), "U1", cmd, "", map[string]string{}); !errors.Is(err, syscall.ECONNREFUSED) {
+		t.Fatalf("run = %v, want the command's own error rather than the breaker's", err)
+	}
+	if runs != 1 {
+		t.Errorf("ran %d times, want once without retries", runs)
+	}
+	if br.failures != breakerThreshold {
+		t.Errorf("breaker counted %d failures, want the command's failure left out", br.failures)
+	}
+}
+
+func TestReloadConfigRefusesAllClusters(t *testing.T) {
+	b := &bot{clusters: &clusters{byName: map[string]*cluster{}}}
+	reply, err := b.respond(context.Background(), message{channel: "C1", user: "U1"}, "k reload-config /etc/mibot/kubeconfig --all-clusters")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(reply, "only works with read-only commands") {
+		t.Errorf("reply = %q, want --all-clusters refused", reply)
+	}
+}
+
+func TestReloadConfigRefusesWatch(t *testing.T) {
+	b := &bot{clusters: &clusters{byName: map[string]*cluster{}}}
+	reply, err := b.respond(context.Background(), message{channel: "C1", user: "U1"}, "watch k reload-config /etc/mibot/kubeconfig")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(reply, "only read-only commands can be watched") {
+		t.Errorf("reply = %q, want watch refused", reply)
+	}
+}
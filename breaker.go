@@ -0,0 +1,112 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+)
+
+const (
+	// breakerThreshold is how many commands in a row have to find a cluster unreachable before it's given a rest
+	breakerThreshold = 3
+	// breakerCooldown is how long commands against a cluster that's given a rest fail straight away, before one
+	// probe checks whether it's back
+	breakerCooldown = 30 * time.Second
+)
+
+// breaker stops commands against a cluster whose API server is down from each paying the API timeout, e.g. every
+// --all-clusters query. It only trips on errors that mean the cluster didn't answer, not on answers like 404.
+type breaker struct {
+	mu       sync.Mutex
+	failures int
+	// openUntil is when the next probe may check the cluster again, once failures reaches breakerThreshold
+	openUntil time.Time
+	// probing is set while a probe is checking the cluster, so only one command waits on it at a time
+	probing bool
+}
+
+// allow reports whether a command may run against the cluster now, and whether it must probe the cluster first
+// because it has been unreachable. Probes must be followed by a call to record.
+func (br *breaker) allow() (ok, probe bool) {
+	br.mu.Lock()
+	defer br.mu.Unlock()
+
+	if br.failures < breakerThreshold {
+		return true, false
+	}
+	if br.probing || time.Now().Before(br.openUntil) {
+		return false, false
+	}
+	br.probing = true
+	return true, true
+}
+
+// record counts whether the cluster was unreachable for a command or probe
+func (br *breaker) record(name string, unreachable bool) {
+	br.mu.Lock()
+	defer br.mu.Unlock()
+
+	br.probing = false
+	if !unreachable {
+		if br.failures >= breakerThreshold {
+			slog.Info("cluster is reachable again", "context", name)
+		}
+		br.failures = 0
+		return
+	}
+
+	br.failures++
+	if br.failures >= breakerThreshold {
+		br.openUntil = time.Now().Add(breakerCooldown)
+		if br.failures == breakerThreshold {
+			slog.Warn("cluster is unreachable, failing its commands straight away", "context", name, "cooldown", breakerCooldown)
+		}
+	}
+}
+
+// unreachable reports whether err means the API server didn't answer at all, as opposed to answering with an error
+func unreachable(err error) bool {
+	return errors.Is(err, context.DeadlineExceeded) ||
+		apierrors.IsServiceUnavailable(err) ||
+		transient(context.Background(), err) && !apierrors.IsTooManyRequests(err)
+}
+
+// checkBreaker returns an error straight away when cl has been unreachable, probing it once each cooldown to see
+// whether it's back
+func (b *bot) checkBreaker(ctx context.Context, cl *cluster) error {
+	ok, probe := cl.breaker.allow()
+	if !ok {
+		return fmt.Errorf("cluster `%s` is currently unavailable", clusterName(cl.name))
+	}
+	if !probe {
+		return nil
+	}
+
+	if b.apiTimeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, b.apiTimeout)
+		defer cancel()
+	}
+	err := cl.clientset.Discovery().RESTClient().Get().AbsPath("/version").Do(ctx).Error()
+	down := err != nil && (errors.Is(ctx.Err(), context.DeadlineExceeded) || unreachable(err))
+	cl.breaker.record(cl.name, down)
+	if down {
+		return fmt.Errorf("cluster `%s` is currently unavailable: %w", clusterName(cl.name), err)
+	}
+
+	return nil
+}
+
+// clusterName names the context called name in replies, where the in-cluster config has no name of its own
+func clusterName(name string) string {
+	if name == "" {
+		return "in-cluster"
+	}
+
+	return name
+}
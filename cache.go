@@ -0,0 +1,67 @@
+package main
+
+import (
+	"sync"
+	"time"
+)
+
+// replyCache remembers recent command replies so identical queries within ttl don't hit the API server again.
+// A zero ttl disables caching.
+type replyCache struct {
+	ttl time.Duration
+
+	mu      sync.Mutex
+	entries map[string]cacheEntry
+}
+
+type cacheEntry struct {
+	reply   string
+	expires time.Time
+}
+
+func newReplyCache(ttl time.Duration) *replyCache {
+	return &replyCache{ttl: ttl, entries: make(map[string]cacheEntry)}
+}
+
+// get returns the cached reply for key if there is one that hasn't expired
+func (c *replyCache) get(key string) (string, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	entry, ok := c.entries[key]
+	if !ok {
+		return "", false
+	}
+	if time.Now().After(entry.expires) {
+		delete(c.entries, key)
+		return "", false
+	}
+
+	return entry.reply, true
+}
+
+// set caches reply under key, dropping any other entries that have expired
+func (c *replyCache) set(key, reply string) {
+	if c.ttl <= 0 {
+		return
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	now := time.Now()
+	for k, entry := range c.entries {
+		if now.After(entry.expires) {
+			delete(c.entries, k)
+		}
+	}
+	c.entries[key] = cacheEntry{reply: reply, expires: now.Add(c.ttl)}
+}
+
+// clear drops every cached reply, e.g. after a command changes cluster state
+func (c *replyCache) clear() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.entries = make(map[string]cacheEntry)
+}
@@ -0,0 +1,191 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/client-go/discovery/cached/memory"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/restmapper"
+	"k8s.io/client-go/tools/clientcmd"
+	metricsclientset "k8s.io/metrics/pkg/client/clientset/versioned"
+)
+
+// contextFlagRegexp matches the --context flag that selects which cluster a command runs against
+var contextFlagRegexp = regexp.MustCompile(`(^|\s)--context[= ](?P<context>\S+)`)
+
+// cluster is everything mibot uses to talk to the cluster behind one kubeconfig context
+type cluster struct {
+	name      string
+	config    *rest.Config
+	clientset kubernetes.Interface
+	metrics   metricsclientset.Interface
+	dynamic   dynamic.Interface
+	// mapper resolves resource names like certs to what the cluster serves, from discovery it caches
+	mapper meta.RESTMapper
+	// listers is the informer cache, nil unless the context's informers have synced
+	listers *listers
+	// legacy is which resources are only served from older API versions, filled in by discoverAPIs
+	legacy legacyAPIs
+	// breaker fails commands straight away while the cluster is unreachable
+	breaker *breaker
+}
+
+// newCluster builds the clients for the context called name from its config
+func newCluster(name string, config *rest.Config) (*cluster, error) {
+	config.Wrap(instrumentTransport)
+	return newClusterClients(name, config)
+}
+
+// newClusterClients builds the clients for config as is
+func newClusterClients(name string, config *rest.Config) (*cluster, error) {
+	clientset, err := kubernetes.NewForConfig(config)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create clientset for context %q: %w", name, err)
+	}
+	metrics, err := metricsclientset.NewForConfig(config)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create metrics clientset for context %q: %w", name, err)
+	}
+
+	dynamicClient, err := dynamic.NewForConfig(config)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create dynamic client for context %q: %w", name, err)
+	}
+
+	// Discovery is only refreshed when a name doesn't resolve, so newly installed CRDs are still found
+	cachedDiscovery := memory.NewMemCacheClient(clientset.Discovery())
+	mapper := restmapper.NewShortcutExpander(restmapper.NewDeferredDiscoveryRESTMapper(cachedDiscovery), cachedDiscovery)
+
+	return &cluster{name: name, config: config, clientset: clientset, metrics: metrics, dynamic: dynamicClient, mapper: mapper, breaker: &breaker{}}, nil
+}
+
+type clusterKey struct{}
+
+// withCluster returns a copy of ctx carrying cl for command handlers that need more than its clientset
+func withCluster(ctx context.Context, cl *cluster) context.Context {
+	return context.WithValue(ctx, clusterKey{}, cl)
+}
+
+// clusterFrom returns the cluster carried by ctx, or nil if there is none
+func clusterFrom(ctx context.Context) *cluster {
+	cl, _ := ctx.Value(clusterKey{}).(*cluster)
+	return cl
+}
+
+// clusters holds the clients for every context in the kubeconfig. They're swapped for another kubeconfig's as a
+// whole by reload-config, so mu guards everything.
+type clusters struct {
+	mu      sync.RWMutex
+	current string
+	byName  map[string]*cluster
+	// stopInformers stops the informers started for byName, if any
+	stopInformers context.CancelFunc
+}
+
+// loadClusters builds the clients for each kubeconfig context. With no kubeconfig contexts it falls back to the
+// in-cluster config under the empty context name.
+func loadClusters(kubeconfig string) (*clusters, error) {
+	loadingRules := &clientcmd.ClientConfigLoadingRules{ExplicitPath: kubeconfig}
+	raw, err := clientcmd.NewNonInteractiveDeferredLoadingClientConfig(loadingRules, &clientcmd.ConfigOverrides{}).RawConfig()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load kubeconfig %q: %w", kubeconfig, err)
+	}
+
+	c := &clusters{current: raw.CurrentContext, byName: make(map[string]*cluster)}
+	if len(raw.Contexts) == 0 {
+		config, err := clientcmd.BuildConfigFromFlags("", kubeconfig)
+		if err != nil {
+			return nil, fmt.Errorf("failed to build kubeconfig from %q: %w", kubeconfig, err)
+		}
+		cl, err := newCluster("", config)
+		if err != nil {
+			return nil, err
+		}
+		c.current = ""
+		c.byName[""] = cl
+		return c, nil
+	}
+
+	for name := range raw.Contexts {
+		config, err := clientcmd.NewNonInteractiveClientConfig(raw, name, &clientcmd.ConfigOverrides{}, loadingRules).ClientConfig()
+		if err != nil {
+			return nil, fmt.Errorf("failed to build config for context %q: %w", name, err)
+		}
+		cl, err := newCluster(name, config)
+		if err != nil {
+			return nil, err
+		}
+		c.byName[name] = cl
+	}
+
+	return c, nil
+}
+
+// get returns the cluster for kubeContext, or the current context's when kubeContext is empty
+func (c *clusters) get(kubeContext string) (*cluster, error) {
+	kubeContext = c.resolve(kubeContext)
+	c.mu.RLock()
+	cl, ok := c.byName[kubeContext]
+	c.mu.RUnlock()
+	if !ok {
+		return nil, fmt.Errorf("unknown context `%s`, try one of: %s", kubeContext, strings.Join(c.names(), ", "))
+	}
+
+	return cl, nil
+}
+
+// resolve maps the empty context name to the current context
+func (c *clusters) resolve(kubeContext string) string {
+	if kubeContext == "" {
+		c.mu.RLock()
+		defer c.mu.RUnlock()
+		return c.current
+	}
+
+	return kubeContext
+}
+
+// names returns every known context name in sorted order
+func (c *clusters) names() []string {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	names := make([]string, 0, len(c.byName))
+	for name := range c.byName {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	return names
+}
+
+// swap replaces every cluster with next's, stopping the informers of those it replaces. next must not be used
+// afterwards.
+func (c *clusters) swap(next *clusters) {
+	c.mu.Lock()
+	stop := c.stopInformers
+	c.current, c.byName, c.stopInformers = next.current, next.byName, next.stopInformers
+	c.mu.Unlock()
+
+	if stop != nil {
+		stop()
+	}
+}
+
+// extractContext removes a --context flag from text, returning the remaining text and the requested context
+func extractContext(text string) (string, string) {
+	match := contextFlagRegexp.FindStringSubmatch(text)
+	if match == nil {
+		return text, ""
+	}
+
+	text = strings.Replace(text, match[0], "", 1)
+	return strings.Join(strings.Fields(text), " "), match[contextFlagRegexp.SubexpIndex("context")]
+}
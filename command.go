@@ -0,0 +1,355 @@
+package main
+
+import (
+	"context"
+	"regexp"
+	"strings"
+
+	"k8s.io/client-go/kubernetes"
+)
+
+// dns1123Label matches a valid Kubernetes namespace name
+const dns1123Label = `[a-z0-9]([-a-z0-9]*[a-z0-9])?`
+
+// namespaceFlag matches -n $namespace
+const namespaceFlag = `-n (?P<namespace>` + dns1123Label + `)`
+
+// namespaceOrAll matches either -n $namespace or -A/--all-namespaces
+const namespaceOrAll = `(` + namespaceFlag + `|(?P<allNamespaces>-A|--all-namespaces))`
+
+// optionalNamespace matches -n $namespace where it may be left out in favour of DEFAULT_NAMESPACE
+const optionalNamespace = `(?: ` + namespaceFlag + `)?`
+
+// getFlags matches the optional flags shared by the get commands, which may appear in any order either side of
+// the namespace, along with a grep $term to filter by name, --problems to list only unhealthy pods and -o wide for
+// more columns
+const getFlags = `(?: -l (?P<selector>\S+)| --field-selector[= ](?P<fieldSelector>\S+)| --sort-by[= ](?P<sortBy>\S+)| grep (?P<grep>\S+)| (?P<problems>--problems)| (?:-o ?|--output[= ])(?P<wide>wide))*`
+
+// Command is a single thing mibot knows how to do in response to a Slack message
+type Command interface {
+	// Matches reports whether text, with the bot mention stripped, invokes this command
+	Matches(text string) bool
+	// Args extracts the command's named arguments from text
+	Args(text string) map[string]string
+	// Handle runs the command and returns the reply to send back to Slack
+	Handle(ctx context.Context, args map[string]string, clientset kubernetes.Interface) (string, error)
+	// Mutating reports whether the command changes cluster state
+	Mutating() bool
+	// Usage shows how to invoke the command, e.g. kubectl get po -n $namespace
+	Usage() string
+	// Description says what the command does in a short sentence
+	Description() string
+}
+
+// defaultCommandPrefixes are the words commands start with unless COMMAND_PREFIX says otherwise
+var defaultCommandPrefixes = []string{"kubectl", "k"}
+
+// commandPrefixes are the words commands start with, the first being how usage and suggestions write them
+var commandPrefixes = defaultCommandPrefixes
+
+// commandDefinitions are the commands defined by COMMANDS_CONFIG, or by defaultCommandsConfig without one
+var commandDefinitions = mustParseCommandDefinitions(defaultCommandsConfig)
+
+// commands is every command mibot understands, in the order they are matched
+var commands = newCommands(defaultCommandPrefixes, commandDefinitions)
+
+// setCommandPrefixes rebuilds the command registry so commands start with one of prefixes instead
+func setCommandPrefixes(prefixes []string) {
+	commandPrefixes = prefixes
+	commands = newCommands(commandPrefixes, commandDefinitions)
+}
+
+// setCommandDefinitions rebuilds the command registry with definitions in place of the default ones
+func setCommandDefinitions(definitions []commandDefinition) {
+	commandDefinitions = definitions
+	commands = newCommands(commandPrefixes, commandDefinitions)
+}
+
+// isCommandPrefix reports whether word is one of the words commands start with
+func isCommandPrefix(word string) bool {
+	for _, prefix := range commandPrefixes {
+		if word == prefix {
+			return true
+		}
+	}
+
+	return false
+}
+
+// newCommands builds the command registry for commands starting with any of prefixes, e.g. kubectl or k, with
+// the configured commands defined by definitions
+func newCommands(prefixes []string, definitions []commandDefinition) []Command {
+	quoted := make([]string, 0, len(prefixes))
+	for _, p := range prefixes {
+		quoted = append(quoted, regexp.QuoteMeta(p))
+	}
+	prefix := `(?:` + strings.Join(quoted, "|") + `)`
+	usage := func(command string) string {
+		return prefixes[0] + " " + command
+	}
+
+	registry := []Command{
+		getAllCommand{newRegexpCommand(
+			`^`+prefix+` get all`+getFlags+optionalNamespace+getFlags+`$`,
+			usage("get all [-n $namespace]"),
+			"Summarize the deployments, services and pods in a namespace",
+		)},
+	}
+	for _, definition := range definitions {
+		registry = append(registry, definition.command(prefix, usage))
+	}
+
+	return append(registry,
+		getStatefulSetCommand{newRegexpCommand(
+			`^`+prefix+` get (sts|statefulsets?)`+getFlags+optionalNamespace+getFlags+`$`,
+			usage("get sts [-n $namespace]"),
+			"List statefulsets and how many of their replicas are ready",
+		)},
+		getDaemonSetCommand{newRegexpCommand(
+			`^`+prefix+` get (ds|daemonsets?)`+getFlags+optionalNamespace+getFlags+`$`,
+			usage("get ds [-n $namespace]"),
+			"List daemonsets and how many of their pods are ready",
+		)},
+		getReplicaSetCommand{newRegexpCommand(
+			`^`+prefix+` get (rs|replicasets?)`+getFlags+optionalNamespace+getFlags+`$`,
+			usage("get rs [-n $namespace]"),
+			"List replicasets and how many of their replicas are ready",
+		)},
+		getJobsCommand{newRegexpCommand(
+			`^`+prefix+` get jobs?`+getFlags+optionalNamespace+getFlags+`$`,
+			usage("get jobs [-n $namespace]"),
+			"List jobs with their completions and how long they ran",
+		)},
+		getCronJobsCommand{newRegexpCommand(
+			`^`+prefix+` get (cj|cronjobs?)`+getFlags+optionalNamespace+getFlags+`$`,
+			usage("get cronjobs [-n $namespace]"),
+			"List cronjobs with their schedule and when they last ran",
+		)},
+		getHPACommand{newRegexpCommand(
+			`^`+prefix+` get (hpa|horizontalpodautoscalers?)`+getFlags+optionalNamespace+getFlags+`$`,
+			usage("get hpa [-n $namespace]"),
+			"List autoscalers with their current and target utilization",
+		)},
+		getSvcCommand{newRegexpCommand(
+			`^`+prefix+` get (svc|service(s)?)`+getFlags+optionalNamespace+getFlags+`$`,
+			usage("get svc [-n $namespace]"),
+			"List services with their cluster IPs and ports",
+		)},
+		getIngressCommand{newRegexpCommand(
+			`^`+prefix+` get (ing|ingress(es)?)`+getFlags+optionalNamespace+getFlags+`$`,
+			usage("get ingress [-n $namespace]"),
+			"List ingresses with their hosts and addresses",
+		)},
+		getEndpointsCommand{newRegexpCommand(
+			`^`+prefix+` get (ep|endpoints|endpointslices?)[ /](?P<name>\S+)`+optionalNamespace+`$`,
+			usage("get endpoints $service [-n $namespace]"),
+			"List the addresses behind a service and whether they're ready",
+		)},
+		getPVCCommand{newRegexpCommand(
+			`^`+prefix+` get (pvc|persistentvolumeclaims?)`+getFlags+optionalNamespace+getFlags+`$`,
+			usage("get pvc [-n $namespace]"),
+			"List persistent volume claims and the volumes they are bound to",
+		)},
+		getPVCommand{newRegexpCommand(
+			`^`+prefix+` get (pv|persistentvolumes?)`+getFlags+`$`,
+			usage("get pv"),
+			"List persistent volumes and the claims bound to them",
+		)},
+		getConfigMapsCommand{newRegexpCommand(
+			`^`+prefix+` get (cm|configmaps?)`+getFlags+optionalNamespace+getFlags+`$`,
+			usage("get cm [-n $namespace]"),
+			"List configmaps and how many keys they have",
+		)},
+		getSecretsCommand{newRegexpCommand(
+			`^`+prefix+` get secrets?`+getFlags+optionalNamespace+getFlags+`$`,
+			usage("get secrets [-n $namespace]"),
+			"List secrets and how many keys they have, never their values",
+		)},
+		getEventsCommand{newRegexpCommand(
+			`^`+prefix+` get (ev|events?)`+getFlags+optionalNamespace+getFlags+`$`,
+			usage("get events [-n $namespace]"),
+			"Show the most recent events, newest first",
+		)},
+		getOutputCommand{newRegexpCommand(
+			`^`+prefix+` get (?P<resource>[a-z]+)[ /](?P<name>\S+)(?: `+namespaceFlag+`)? (?:-o ?|--output[= ])(?P<output>json|yaml)(?: `+namespaceFlag+`)?(?P<showManagedFields> --show-managed-fields)?$`,
+			usage("get $resource $name [-n $namespace] -o json|yaml"),
+			"Dump a single object, without its managedFields unless you add --show-managed-fields",
+		)},
+		getNamespacesCommand{newRegexpCommand(
+			`^`+prefix+` get (ns|namespaces?)`+getFlags+`$`,
+			usage("get ns"),
+			"List the namespaces you can use mibot in",
+		)},
+		getNodesCommand{newRegexpCommand(
+			`^`+prefix+` get (no|nodes?)`+getFlags+`$`,
+			usage("get nodes"),
+			"List nodes with their status, roles and kubelet version",
+		)},
+		topPodsCommand{newRegexpCommand(
+			`^`+prefix+` top po(d)?(s)?`+getFlags+optionalNamespace+getFlags+`$`,
+			usage("top pods [-n $namespace]"),
+			"Show pod CPU and memory usage, busiest first",
+		)},
+		topNodesCommand{newRegexpCommand(
+			`^`+prefix+` top (no|nodes?)`+getFlags+`$`,
+			usage("top nodes"),
+			"Show node CPU and memory usage, busiest first",
+		)},
+		versionCommand{newRegexpCommand(
+			`^(`+prefix+` )?version$`,
+			usage("version"),
+			"Show the versions of mibot, Go and the cluster",
+		)},
+		explainCommand{newRegexpCommand(
+			`^`+prefix+` explain( (?P<resource>[a-z]+))?$`,
+			usage("explain $resource"),
+			"Explain a resource and the columns mibot shows for it",
+		)},
+		diffCommand{newRegexpCommand(
+			`^`+prefix+` diff (?P<manifest>.+)$`,
+			usage("diff $manifest"),
+			"Compare the fields a manifest pasted in a code block sets to the live object",
+		)},
+		pingCommand{newRegexpCommand(
+			`^(`+prefix+` )?ping$`,
+			usage("ping"),
+			"Show how quickly Slack and the Kubernetes API are answering",
+		)},
+		reloadConfigCommand{newRegexpCommand(
+			`^(`+prefix+` )?reload-config (?P<path>\S+)$`,
+			usage("reload-config $path"),
+			"Switch every cluster to those of the kubeconfig at $path, for admins only",
+		)},
+		whoamiCommand{newRegexpCommand(
+			`^(`+prefix+` )?(auth whoami|whoami|whereami)$`,
+			usage("whoami"),
+			"Show which cluster mibot is talking to, who it is there and which namespaces it may use",
+		)},
+		logsCommand{newRegexpCommand(
+			`^`+prefix+` logs(?: (?P<follow>-f|--follow)| (?P<previous>-p|--previous))* (?P<pod>\S+) `+namespaceFlag+`( -c (?P<container>\S+))?( --since[= ](?P<since>\S+))?( grep (?P<grep>\S+))?$`,
+			usage("logs [-f] [-p] $pod -n $namespace [-c $container] [--since=$duration] [grep $regex]"),
+			"Show the last lines of a container's logs, or its previous instance's with -p, or follow them with -f, keeping those matching grep $regex",
+		)},
+		scaleCommand{mutatingCommand{newRegexpCommand(
+			`^`+prefix+` scale deploy(ment)?(s)?[ /](?P<name>\S+)( --replicas=(?P<replicas>\S*))? `+namespaceFlag+`$`,
+			usage("scale deploy $name --replicas=$n -n $namespace"),
+			"Change the number of replicas of a deployment",
+		)}},
+		rolloutRestartCommand{mutatingCommand{newRegexpCommand(
+			`^`+prefix+` rollout restart deploy(ment)?(s)?[ /](?P<name>\S+) `+namespaceFlag+`$`,
+			usage("rollout restart deploy $name -n $namespace"),
+			"Roll every pod of a deployment",
+		)}},
+		rolloutStatusCommand{newRegexpCommand(
+			`^`+prefix+` rollout status deploy(ment)?(s)?[ /](?P<name>\S+) `+namespaceFlag+`$`,
+			usage("rollout status deploy $name -n $namespace"),
+			"Show whether a deployment has finished rolling out",
+		)},
+		rolloutHistoryCommand{newRegexpCommand(
+			`^`+prefix+` rollout history deploy(ment)?(s)?[ /](?P<name>\S+) `+namespaceFlag+`$`,
+			usage("rollout history deploy $name -n $namespace"),
+			"List a deployment's revisions and the images each ran, newest first",
+		)},
+		imageCommand{newRegexpCommand(
+			`^`+prefix+` images? deploy(ment)?(s)?[ /](?P<name>\S+) `+namespaceFlag+`$`,
+			usage("image deploy $name -n $namespace"),
+			"Show the image of each of a deployment's containers, init containers included",
+		)},
+		deletePodCommand{mutatingCommand{newRegexpCommand(
+			`^`+prefix+` delete po(d)?(s)?[ /](?P<pod>\S+) `+namespaceFlag+`$`,
+			usage("delete po $pod -n $namespace"),
+			"Delete a pod so it gets rescheduled, once you confirm",
+		)}},
+		cordonCommand{mutatingCommand{newRegexpCommand(
+			`^`+prefix+` (?P<verb>cordon|uncordon) (?:no(de)?(s)?[ /])?(?P<node>\S+)$`,
+			usage("cordon|uncordon $node"),
+			"Stop or resume scheduling pods on a node, for admins once they confirm",
+		)}},
+		drainCommand{mutatingCommand{newRegexpCommand(
+			`^`+prefix+` drain (?:no(de)?(s)?[ /])?(?P<node>\S+)(?: --grace-period[= ](?P<gracePeriod>-?\d+))?$`,
+			usage("drain $node [--grace-period=$seconds]"),
+			"Cordon a node and evict its pods, except DaemonSets', for admins once they confirm",
+		)}},
+		execCommand{mutatingCommand{newRegexpCommand(
+			`^`+prefix+` exec (?P<pod>\S+) `+namespaceFlag+`( -c (?P<container>\S+))? -- (?P<command>.+)$`,
+			usage("exec $pod -n $namespace [-c $container] -- $command"),
+			"Run one of the commands in EXEC_ALLOWED_CMDS in a container and upload its output",
+		)}},
+		describePodCommand{newRegexpCommand(
+			`^`+prefix+` describe po(d)?(s)? (?P<pod>\S+) `+namespaceFlag+`$`,
+			usage("describe po $pod -n $namespace"),
+			"Show the details and recent events of a pod",
+		)},
+		// Anything else get is asked for is looked up in discovery, so this has to come after every other get
+		getResourceCommand{newRegexpCommand(
+			`^`+prefix+` get (?P<resource>[a-z][-a-z0-9.]*)`+getFlags+`(?: `+namespaceOrAll+`)?`+getFlags+`$`,
+			usage("get $resource [-n $namespace|-A]"),
+			"List any other resource the cluster serves, e.g. a CRD's, by name and age",
+		)},
+	)
+}
+
+// findCommand returns the first registered command matching text, or nil if there is none
+func findCommand(text string) Command {
+	for _, cmd := range commands {
+		if cmd.Matches(text) {
+			return cmd
+		}
+	}
+
+	return nil
+}
+
+// regexpCommand implements everything but Handle for a command defined by a regular expression with named
+// subexpressions
+type regexpCommand struct {
+	re          *regexp.Regexp
+	usage       string
+	description string
+}
+
+func newRegexpCommand(expr, usage, description string) regexpCommand {
+	return regexpCommand{re: regexp.MustCompile(expr), usage: usage, description: description}
+}
+
+func (c regexpCommand) Matches(text string) bool {
+	return c.re.MatchString(text)
+}
+
+func (c regexpCommand) Args(text string) map[string]string {
+	return regexpSubexpMatch(c.re, text)
+}
+
+func (regexpCommand) Mutating() bool {
+	return false
+}
+
+func (c regexpCommand) Usage() string {
+	return c.usage
+}
+
+func (c regexpCommand) Description() string {
+	return c.description
+}
+
+// mutatingCommand marks an embedding command as one that changes cluster state
+type mutatingCommand struct {
+	regexpCommand
+}
+
+func (mutatingCommand) Mutating() bool {
+	return true
+}
+
+func regexpSubexpMatch(r *regexp.Regexp, str string) map[string]string {
+	match := r.FindStringSubmatch(str)
+	subexpMatchMap := make(map[string]string)
+	for i, name := range r.SubexpNames() {
+		// A name can appear more than once, e.g. flags allowed either side of an argument, so keep whichever matched
+		if _, ok := subexpMatchMap[name]; i != 0 && (!ok || match[i] != "") {
+			subexpMatchMap[name] = match[i]
+		}
+	}
+
+	return subexpMatchMap
+}
@@ -0,0 +1,20 @@
+package main
+
+import "testing"
+
+func TestFindCommandAnchorsWholeMessage(t *testing.T) {
+	if cmd := findCommand("please don't k get deploy -n foo right now"); cmd != nil {
+		t.Errorf("matched %q, want no command for text around a command", cmd.Usage())
+	}
+
+	cmd := findCommand("k get deploy -n foo")
+	if cmd == nil {
+		t.Fatal("k get deploy -n foo matched no command")
+	}
+	if _, ok := cmd.(getDeployCommand); !ok {
+		t.Errorf("k get deploy -n foo matched %T, want getDeployCommand", cmd)
+	}
+	if namespace := cmd.Args("k get deploy -n foo")["namespace"]; namespace != "foo" {
+		t.Errorf("namespace = %q, want foo", namespace)
+	}
+}
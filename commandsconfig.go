@@ -0,0 +1,159 @@
+package main
+
+import (
+	_ "embed"
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+// defaultCommandsConfig defines the configured commands when COMMANDS_CONFIG isn't set
+//
+//go:embed commands.yaml
+var defaultCommandsConfig []byte
+
+// patternPlaceholders expands the placeholders a configured command's pattern may use for mibot's common flags
+var patternPlaceholders = strings.NewReplacer("{namespace}", namespaceFlag, "{namespaceOrAll}", namespaceOrAll, "{getFlags}", getFlags)
+
+// commandDefinition is a command defined in configuration rather than code, see commands.yaml
+type commandDefinition struct {
+	Name        string `yaml:"name"`
+	Pattern     string `yaml:"pattern"`
+	Usage       string `yaml:"usage"`
+	Description string `yaml:"description"`
+	Group       string `yaml:"group"`
+	Version     string `yaml:"version"`
+	Resource    string `yaml:"resource"`
+	Verb        string `yaml:"verb"`
+	// Columns are shown between NAME and AGE for resources without a built-in handler
+	Columns []columnDefinition `yaml:"columns"`
+}
+
+// columnDefinition is a column of a configured command's table, read from each object's field at path, e.g.
+// status.phase
+type columnDefinition struct {
+	Name string `yaml:"name"`
+	Path string `yaml:"path"`
+}
+
+// builtinKey identifies a resource and verb with a built-in handler
+type builtinKey struct {
+	resource schema.GroupVersionResource
+	verb     string
+}
+
+// builtinCommands are the handlers configured commands use instead of resourceCommand for resources we know
+// how to show better
+var builtinCommands = map[builtinKey]func(regexpCommand) Command{
+	{schema.GroupVersionResource{Group: "apps", Version: "v1", Resource: "deployments"}, "list"}: func(c regexpCommand) Command {
+		return getDeployCommand{c}
+	},
+	{schema.GroupVersionResource{Version: "v1", Resource: "pods"}, "list"}: func(c regexpCommand) Command {
+		return getPodCommand{c}
+	},
+}
+
+// loadCommandDefinitions reads the configured commands from the YAML file at path
+func loadCommandDefinitions(path string) ([]commandDefinition, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read commands config %q: %w", path, err)
+	}
+	definitions, err := parseCommandDefinitions(data)
+	if err != nil {
+		return nil, fmt.Errorf("invalid commands config %q: %w", path, err)
+	}
+
+	return definitions, nil
+}
+
+// mustParseCommandDefinitions parses built-in configuration, which is a bug to get wrong
+func mustParseCommandDefinitions(data []byte) []commandDefinition {
+	definitions, err := parseCommandDefinitions(data)
+	if err != nil {
+		panic(err)
+	}
+
+	return definitions
+}
+
+// parseCommandDefinitions parses and validates configured commands, referring to the line at fault in errors
+func parseCommandDefinitions(data []byte) ([]commandDefinition, error) {
+	var config struct {
+		Commands []yaml.Node `yaml:"commands"`
+	}
+	if err := yaml.Unmarshal(data, &config); err != nil {
+		return nil, err
+	}
+
+	definitions := make([]commandDefinition, 0, len(config.Commands))
+	lines := make(map[string]int)
+	for _, node := range config.Commands {
+		var d commandDefinition
+		if err := node.Decode(&d); err != nil {
+			return nil, err
+		}
+		if err := d.validate(); err != nil {
+			return nil, fmt.Errorf("line %d: command %q: %w", node.Line, d.Name, err)
+		}
+		if line, ok := lines[d.Name]; ok {
+			return nil, fmt.Errorf("line %d: command %q is already defined on line %d", node.Line, d.Name, line)
+		}
+		lines[d.Name] = node.Line
+		definitions = append(definitions, d)
+	}
+
+	return definitions, nil
+}
+
+// validate checks d has everything needed to build a working command
+func (d commandDefinition) validate() error {
+	switch {
+	case d.Name == "":
+		return fmt.Errorf("name is required")
+	case d.Pattern == "":
+		return fmt.Errorf("pattern is required")
+	case d.Usage == "" || d.Description == "":
+		return fmt.Errorf("usage and description are required for help")
+	case d.Version == "" || d.Resource == "":
+		return fmt.Errorf("version and resource are required")
+	case d.Verb != "list" && d.Verb != "get":
+		return fmt.Errorf("verb must be list or get, not %q", d.Verb)
+	}
+	for _, column := range d.Columns {
+		if column.Name == "" || column.Path == "" {
+			return fmt.Errorf("columns need a name and a path")
+		}
+	}
+
+	re, err := regexp.Compile(d.expr(""))
+	if err != nil {
+		return fmt.Errorf("invalid pattern: %w", err)
+	}
+	if d.Verb == "get" && re.SubexpIndex("name") == -1 {
+		return fmt.Errorf("pattern must have a (?P<name>...) group for verb get")
+	}
+
+	return nil
+}
+
+// expr is the regexp matching d's commands starting with prefix. The pattern is grouped so an alternation in it,
+// e.g. get widgets|get wd, stays inside the anchors rather than letting either side match text around a command.
+func (d commandDefinition) expr(prefix string) string {
+	return `^` + prefix + ` (?:` + patternPlaceholders.Replace(d.Pattern) + `)$`
+}
+
+// command builds the command d defines for commands starting with prefix, using usage to write its usage
+func (d commandDefinition) command(prefix string, usage func(string) string) Command {
+	c := newRegexpCommand(d.expr(prefix), usage(d.Usage), d.Description)
+	resource := schema.GroupVersionResource{Group: d.Group, Version: d.Version, Resource: d.Resource}
+	if builtin, ok := builtinCommands[builtinKey{resource, d.Verb}]; ok {
+		return builtin(c)
+	}
+
+	return resourceCommand{regexpCommand: c, resource: resource, verb: d.Verb, columns: d.Columns}
+}
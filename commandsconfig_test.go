@@ -0,0 +1,32 @@
+package main
+
+import "testing"
+
+func TestConfiguredPatternAlternationIsAnchored(t *testing.T) {
+	d := commandDefinition{
+		Name:        "widgets",
+		Pattern:     "get widgets {namespace}|get wd {namespace}",
+		Usage:       "get widgets -n $namespace",
+		Description: "List widgets",
+		Group:       "example.com",
+		Version:     "v1",
+		Resource:    "widgets",
+		Verb:        "list",
+	}
+	if err := d.validate(); err != nil {
+		t.Fatal(err)
+	}
+	cmd := d.command("k", func(usage string) string { return "k " + usage })
+
+	for text, want := range map[string]bool{
+		"k get widgets -n foo":                 true,
+		"k get wd -n foo":                      true,
+		"k get widgets -n foo and delete them": false,
+		"please k get wd -n foo":               false,
+		"get wd -n foo":                        false,
+	} {
+		if got := cmd.Matches(text); got != want {
+			t.Errorf("Matches(%q) = %t, want %t", text, got, want)
+		}
+	}
+}
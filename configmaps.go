@@ -0,0 +1,62 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+
+	"k8s.io/client-go/kubernetes"
+)
+
+// getConfigMapsCommand lists ConfigMaps, i.e. kubectl get cm
+type getConfigMapsCommand struct {
+	regexpCommand
+}
+
+func (getConfigMapsCommand) Handle(ctx context.Context, args map[string]string, clientset kubernetes.Interface) (string, error) {
+	listOptions, err := listOptionsFromArgs(args)
+	if err != nil {
+		return "", err
+	}
+	list, err := clientset.CoreV1().ConfigMaps(args["namespace"]).List(ctx, listOptions)
+	if err != nil {
+		return "", fmt.Errorf("failed to list configmaps in `%s`: %w", args["namespace"], err)
+	}
+	if len(list.Items) == 0 {
+		return "No resources found in " + args["namespace"], nil
+	}
+
+	rows := make([][]string, 0, len(list.Items))
+	for _, cm := range list.Items {
+		rows = append(rows, []string{cm.Name, strconv.Itoa(len(cm.Data) + len(cm.BinaryData)), age(cm.CreationTimestamp)})
+	}
+
+	return renderSortedTable(args, []string{"NAME", "DATA", "AGE"}, rows)
+}
+
+// getSecretsCommand lists Secrets, i.e. kubectl get secrets. Only the number of keys is ever shown so the bot
+// can't be used to leak secret contents into a channel.
+type getSecretsCommand struct {
+	regexpCommand
+}
+
+func (getSecretsCommand) Handle(ctx context.Context, args map[string]string, clientset kubernetes.Interface) (string, error) {
+	listOptions, err := listOptionsFromArgs(args)
+	if err != nil {
+		return "", err
+	}
+	list, err := clientset.CoreV1().Secrets(args["namespace"]).List(ctx, listOptions)
+	if err != nil {
+		return "", fmt.Errorf("failed to list secrets in `%s`: %w", args["namespace"], err)
+	}
+	if len(list.Items) == 0 {
+		return "No resources found in " + args["namespace"], nil
+	}
+
+	rows := make([][]string, 0, len(list.Items))
+	for _, secret := range list.Items {
+		rows = append(rows, []string{secret.Name, string(secret.Type), strconv.Itoa(len(secret.Data)), age(secret.CreationTimestamp)})
+	}
+
+	return renderSortedTable(args, []string{"NAME", "TYPE", "DATA", "AGE"}, rows)
+}
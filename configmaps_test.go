@@ -0,0 +1,40 @@
+package main
+
+import (
+	"context"
+	"encoding/base64"
+	"regexp"
+	"strings"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func TestGetSecretsHidesContents(t *testing.T) {
+	password := "correct-horse-battery-staple"
+	clientset := fake.NewSimpleClientset(&corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "db", Namespace: "team-a"},
+		Type:       corev1.SecretTypeOpaque,
+		Data:       map[string][]byte{"username": []byte("admin"), "password": []byte(password)},
+	})
+
+	reply, err := getSecretsCommand{}.Handle(context.Background(), map[string]string{"namespace": "team-a"}, clientset)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, value := range []string{password, base64.StdEncoding.EncodeToString([]byte(password)), "admin", base64.StdEncoding.EncodeToString([]byte("admin"))} {
+		if strings.Contains(reply, value) {
+			t.Errorf("reply %q contains secret data %q", reply, value)
+		}
+	}
+	if payload := regexp.MustCompile(`[A-Za-z0-9+/]{16,}={0,2}`).FindString(reply); payload != "" {
+		t.Errorf("reply %q contains base64-like %q", reply, payload)
+	}
+
+	_, rows, ok := parseTable(reply)
+	if !ok || len(rows) != 1 || rows[0][2] != "2" {
+		t.Errorf("reply %q doesn't show the secret with 2 keys", reply)
+	}
+}
@@ -0,0 +1,76 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// confirmationWindow is how long a user has to confirm a destructive command
+const confirmationWindow = 30 * time.Second
+
+// confirmedCommand is implemented by commands that only run once the user confirms them
+type confirmedCommand interface {
+	// ConfirmationPrompt describes what running the command with args will do, e.g. "delete pod foo in `bar`"
+	ConfirmationPrompt(args map[string]string) string
+}
+
+// pendingAction is a command waiting to be confirmed. It's stored as its text rather than the command itself so
+// it means the same thing to whichever mibot takes it.
+type pendingAction struct {
+	Text        string            `json:"text"`
+	KubeContext string            `json:"kubeContext,omitempty"`
+	Args        map[string]string `json:"args,omitempty"`
+}
+
+// command finds the command the action runs again
+func (a pendingAction) command() Command {
+	return findCommand(a.Text)
+}
+
+// confirmations holds at most one pending action per user and channel
+type confirmations struct {
+	store Store
+}
+
+func newConfirmations(store Store) *confirmations {
+	return &confirmations{store: store}
+}
+
+// add makes action the pending action for user in channel, replacing any previous one
+func (c *confirmations) add(user, channel string, action pendingAction) error {
+	value, err := json.Marshal(action)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), storeTimeout)
+	defer cancel()
+	if err := c.store.Put(ctx, "confirm/"+user+"/"+channel, value, confirmationWindow); err != nil {
+		return fmt.Errorf("failed to save the command to confirm: %w", err)
+	}
+
+	return nil
+}
+
+// take removes and returns the pending action for user in channel, if there is one that hasn't expired
+func (c *confirmations) take(user, channel string) (pendingAction, bool, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), storeTimeout)
+	defer cancel()
+	value, ok, err := c.store.Take(ctx, "confirm/"+user+"/"+channel)
+	if err != nil {
+		return pendingAction{}, false, fmt.Errorf("failed to look up the command to confirm: %w", err)
+	}
+	if !ok {
+		return pendingAction{}, false, nil
+	}
+
+	var action pendingAction
+	if err := json.Unmarshal(value, &action); err != nil || action.command() == nil {
+		// Left behind by a mibot that understood it differently
+		return pendingAction{}, false, nil
+	}
+
+	return action, true, nil
+}
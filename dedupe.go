@@ -0,0 +1,64 @@
+package main
+
+import (
+	"container/list"
+	"sync"
+	"time"
+)
+
+const (
+	// dedupeWindow is how long a message is remembered, comfortably longer than Slack takes to redeliver an event
+	dedupeWindow = 5 * time.Minute
+	// dedupeSize is the most messages remembered at once, the oldest being forgotten first
+	dedupeSize = 1024
+)
+
+// recentMessages remembers the messages seen lately so ones Slack redelivers are only handled once. It's a
+// bounded LRU keyed by channel and timestamp, which together identify a Slack message.
+type recentMessages struct {
+	window time.Duration
+	size   int
+
+	mu      sync.Mutex
+	order   *list.List
+	entries map[string]*list.Element
+}
+
+type recentMessage struct {
+	key  string
+	seen time.Time
+}
+
+func newRecentMessages(window time.Duration, size int) *recentMessages {
+	return &recentMessages{window: window, size: size, order: list.New(), entries: make(map[string]*list.Element)}
+}
+
+// firstSighting records m as seen, reporting false if it already was within the window
+func (r *recentMessages) firstSighting(m message) bool {
+	// Messages without a timestamp, e.g. from slash commands, can't be told apart
+	if m.timestamp == "" {
+		return true
+	}
+	key := m.channel + "/" + m.timestamp
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	now := time.Now()
+	if e, ok := r.entries[key]; ok && now.Sub(e.Value.(*recentMessage).seen) < r.window {
+		r.order.MoveToFront(e)
+		return false
+	} else if ok {
+		e.Value.(*recentMessage).seen = now
+		r.order.MoveToFront(e)
+		return true
+	}
+
+	r.entries[key] = r.order.PushFront(&recentMessage{key: key, seen: now})
+	for r.order.Len() > r.size {
+		oldest := r.order.Back()
+		r.order.Remove(oldest)
+		delete(r.entries, oldest.Value.(*recentMessage).key)
+	}
+	return true
+}
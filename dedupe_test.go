@@ -0,0 +1,38 @@
+package main
+
+import (
+	"context"
+	"testing"
+)
+
+func TestDispatchDropsRedeliveredMessages(t *testing.T) {
+	queue := make(chan message, workerQueueSize)
+	b := &bot{seen: newRecentMessages(dedupeWindow, dedupeSize), workers: []chan message{queue}}
+
+	m := message{channel: "C1", user: "U1", text: "<@UBOT> k get po", timestamp: "1700000000.000100"}
+	b.dispatch(context.Background(), m)
+	b.dispatch(context.Background(), m)
+	b.dispatch(context.Background(), message{channel: "C1", user: "U1", text: "<@UBOT> k get po", timestamp: "1700000000.000200"})
+
+	if len(queue) != 2 {
+		t.Fatalf("queued %d messages, want the redelivered one dropped", len(queue))
+	}
+	if got := <-queue; got != m {
+		t.Errorf("queued %+v first, want %+v", got, m)
+	}
+}
+
+func TestFirstSightingForgetsOldestBeyondSize(t *testing.T) {
+	r := newRecentMessages(dedupeWindow, 2)
+	for _, ts := range []string{"1", "2", "3"} {
+		if !r.firstSighting(message{channel: "C1", timestamp: ts}) {
+			t.Fatalf("message %s reported as seen before", ts)
+		}
+	}
+	if r.firstSighting(message{channel: "C1", timestamp: "3"}) {
+		t.Error("message 3 wasn't remembered")
+	}
+	if !r.firstSighting(message{channel: "C1", timestamp: "1"}) {
+		t.Error("message 1 was remembered past the size limit")
+	}
+}
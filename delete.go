@@ -0,0 +1,31 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+// deletePodCommand deletes a pod so its controller reschedules it, i.e. kubectl delete pod
+type deletePodCommand struct {
+	mutatingCommand
+}
+
+func (deletePodCommand) ConfirmationPrompt(args map[string]string) string {
+	return fmt.Sprintf("delete pod `%s` in `%s`", args["pod"], args["namespace"])
+}
+
+func (deletePodCommand) Handle(ctx context.Context, args map[string]string, clientset kubernetes.Interface) (string, error) {
+	if args["pod"] == "" {
+		return "", errors.New("a pod name is required")
+	}
+
+	if err := clientset.CoreV1().Pods(args["namespace"]).Delete(ctx, args["pod"], metav1.DeleteOptions{}); err != nil {
+		return "", fmt.Errorf("failed to delete pod `%s` in `%s`: %w", args["pod"], args["namespace"], err)
+	}
+
+	return fmt.Sprintf("pod \"%s\" deleted", args["pod"]), nil
+}
@@ -0,0 +1,79 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+// describeEventLimit is how many of a pod's most recent events kubectl describe pod shows
+const describeEventLimit = 10
+
+// describePodCommand shows the details of a single pod, i.e. kubectl describe pod
+type describePodCommand struct {
+	regexpCommand
+}
+
+func (describePodCommand) Handle(ctx context.Context, args map[string]string, clientset kubernetes.Interface) (string, error) {
+	pod, err := clientset.CoreV1().Pods(args["namespace"]).Get(ctx, args["pod"], metav1.GetOptions{})
+	if err != nil {
+		return "", fmt.Errorf("failed to get pod `%s` in `%s`: %w", args["pod"], args["namespace"], err)
+	}
+	events, err := clientset.CoreV1().Events(args["namespace"]).List(ctx, metav1.ListOptions{
+		FieldSelector: "involvedObject.name=" + pod.Name,
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to list events for pod `%s` in `%s`: %w", args["pod"], args["namespace"], err)
+	}
+
+	return "```\n" + describePod(pod, events.Items) + "```", nil
+}
+
+// describePod renders the fields of a pod that are most useful for triage, along with its recent events
+func describePod(pod *corev1.Pod, events []corev1.Event) string {
+	var b strings.Builder
+	b.WriteString("Name:\t" + pod.Name + "\n")
+	b.WriteString("Namespace:\t" + pod.Namespace + "\n")
+	b.WriteString("Node:\t" + pod.Spec.NodeName + "\n")
+	if pod.Status.StartTime != nil {
+		b.WriteString("Start Time:\t" + pod.Status.StartTime.Format(time.RFC1123Z) + "\n")
+	}
+	b.WriteString("Status:\t" + string(pod.Status.Phase) + "\n")
+
+	b.WriteString("Containers:\n")
+	for _, cs := range pod.Status.ContainerStatuses {
+		b.WriteString("  " + cs.Name + ":\n")
+		b.WriteString("    Ready:\t" + strconv.FormatBool(cs.Ready) + "\n")
+		b.WriteString("    Restart Count:\t" + strconv.Itoa(int(cs.RestartCount)) + "\n")
+		if t := cs.LastTerminationState.Terminated; t != nil {
+			b.WriteString("    Last State:\tTerminated\n")
+			b.WriteString("      Reason:\t" + t.Reason + "\n")
+			b.WriteString("      Exit Code:\t" + strconv.Itoa(int(t.ExitCode)) + "\n")
+			b.WriteString("      Finished:\t" + t.FinishedAt.Format(time.RFC1123Z) + "\n")
+		}
+	}
+
+	b.WriteString("Events:\n")
+	if len(events) == 0 {
+		b.WriteString("  <none>\n")
+		return b.String()
+	}
+	sort.Slice(events, func(i, j int) bool {
+		return events[i].LastTimestamp.Before(&events[j].LastTimestamp)
+	})
+	if len(events) > describeEventLimit {
+		events = events[len(events)-describeEventLimit:]
+	}
+	for _, e := range events {
+		b.WriteString("  " + e.Type + "\t" + e.Reason + "\t" + e.Message + "\n")
+	}
+
+	return b.String()
+}
@@ -0,0 +1,168 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"html"
+	"regexp"
+	"strings"
+
+	"github.com/pmezard/go-difflib/difflib"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/kubernetes"
+	"sigs.k8s.io/yaml"
+)
+
+type messageTextKey struct{}
+
+// withMessageText returns a copy of ctx carrying the text of the message being handled as Slack sent it, for
+// commands whose arguments need the line breaks and indentation that matching a command loses
+func withMessageText(ctx context.Context, text string) context.Context {
+	return context.WithValue(ctx, messageTextKey{}, text)
+}
+
+// slackLinkRegexp matches the <url|label> and <url> links Slack turns anything link-like into, e.g. image names
+var slackLinkRegexp = regexp.MustCompile(`<([^|>]+)(?:\|([^>]+))?>`)
+
+// manifestFrom returns the manifest in the first ``` code block of text, or all of fallback if there isn't one,
+// undoing Slack's formatting. A yaml or json language tag on the first line of the block is dropped.
+func manifestFrom(text, fallback string) string {
+	manifest := fallback
+	if _, block, ok := strings.Cut(text, "```"); ok {
+		block, _, _ = strings.Cut(block, "```")
+		if first, rest, ok := strings.Cut(block, "\n"); ok && (strings.TrimSpace(first) == "yaml" || strings.TrimSpace(first) == "json") {
+			block = rest
+		}
+		manifest = block
+	}
+
+	manifest = slackLinkRegexp.ReplaceAllStringFunc(manifest, func(link string) string {
+		match := slackLinkRegexp.FindStringSubmatch(link)
+		if match[2] != "" {
+			return match[2]
+		}
+		return strings.TrimPrefix(match[1], "mailto:")
+	})
+	return html.UnescapeString(manifest)
+}
+
+// diffCommand compares a pasted manifest to the live object it describes, i.e. kubectl diff. Only the fields the
+// manifest sets are compared, so defaults and status the cluster fills in don't show up as drift.
+type diffCommand struct {
+	regexpCommand
+}
+
+func (diffCommand) Handle(ctx context.Context, args map[string]string, _ kubernetes.Interface) (string, error) {
+	cl := clusterFrom(ctx)
+	if cl == nil || cl.mapper == nil {
+		return "", errors.New("no discovery for this cluster")
+	}
+
+	text, _ := ctx.Value(messageTextKey{}).(string)
+	var desired unstructured.Unstructured
+	if err := yaml.Unmarshal([]byte(manifestFrom(text, args["manifest"])), &desired.Object); err != nil {
+		return "", fmt.Errorf("failed to parse the manifest, paste a single object in a ``` code block: %w", err)
+	}
+	gvk := desired.GroupVersionKind()
+	if gvk.Kind == "" || gvk.Version == "" || desired.GetName() == "" {
+		return "the manifest needs an apiVersion, kind and metadata.name", nil
+	}
+
+	mapping, err := cl.mapper.RESTMapping(gvk.GroupKind(), gvk.Version)
+	if meta.IsNoMatchError(err) {
+		return fmt.Sprintf("the cluster doesn't serve `%s` in `%s`", gvk.Kind, gvk.GroupVersion()), nil
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to resolve `%s`: %w", gvk.Kind, err)
+	}
+	// Like -o yaml, the bot never shows a Secret's contents, and the live side of a diff would
+	if mapping.GroupVersionKind.GroupKind() == (schema.GroupKind{Kind: "Secret"}) {
+		return "secrets can't be diffed, mibot never shows their contents", nil
+	}
+
+	resource := cl.dynamic.Resource(mapping.Resource)
+	name := desired.GetName()
+	var live *unstructured.Unstructured
+	if mapping.Scope.Name() == meta.RESTScopeNameNamespace {
+		namespace := desired.GetNamespace()
+		if namespace == "" {
+			namespace = metav1.NamespaceDefault
+		}
+		if !namespaceVisible(ctx, namespace) {
+			return fmt.Sprintf("namespace `%s` is not accessible via mibot", namespace), nil
+		}
+		live, err = resource.Namespace(namespace).Get(ctx, name, metav1.GetOptions{})
+		name = namespace + "/" + name
+	} else {
+		live, err = resource.Get(ctx, name, metav1.GetOptions{})
+	}
+	if apierrors.IsNotFound(err) {
+		return fmt.Sprintf("%s `%s` doesn't exist yet, applying the manifest would create it", gvk.Kind, name), nil
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to get %s %s: %w", gvk.Kind, name, err)
+	}
+
+	want, err := yaml.Marshal(desired.Object)
+	if err != nil {
+		return "", err
+	}
+	have, err := yaml.Marshal(projectFields(live.Object, desired.Object))
+	if err != nil {
+		return "", err
+	}
+	diff, err := difflib.GetUnifiedDiffString(difflib.UnifiedDiff{
+		A:        difflib.SplitLines(string(have)),
+		B:        difflib.SplitLines(string(want)),
+		FromFile: "live",
+		ToFile:   "manifest",
+		Context:  3,
+	})
+	if err != nil {
+		return "", err
+	}
+	if diff == "" {
+		return fmt.Sprintf("%s `%s` matches the manifest", gvk.Kind, name), nil
+	}
+
+	return "```\n" + diff + "```", nil
+}
+
+// Uncached makes every diff compare against the object as it is now
+func (diffCommand) Uncached() {}
+
+// projectFields returns the parts of live at the fields desired sets. Lists are compared item by item when they're
+// the same length, and whole otherwise.
+func projectFields(live, desired interface{}) interface{} {
+	switch desired := desired.(type) {
+	case map[string]interface{}:
+		liveMap, ok := live.(map[string]interface{})
+		if !ok {
+			return live
+		}
+		projected := make(map[string]interface{}, len(desired))
+		for key, value := range desired {
+			if liveValue, ok := liveMap[key]; ok {
+				projected[key] = projectFields(liveValue, value)
+			}
+		}
+		return projected
+	case []interface{}:
+		liveList, ok := live.([]interface{})
+		if !ok || len(liveList) != len(desired) {
+			return live
+		}
+		projected := make([]interface{}, len(desired))
+		for i := range desired {
+			projected[i] = projectFields(liveList[i], desired[i])
+		}
+		return projected
+	default:
+		return live
+	}
+}
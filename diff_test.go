@@ -0,0 +1,46 @@
+package main
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func TestDiffRefusesSecrets(t *testing.T) {
+	secret := &corev1.Secret{
+		TypeMeta:   metav1.TypeMeta{APIVersion: "v1", Kind: "Secret"},
+		ObjectMeta: metav1.ObjectMeta{Name: "db", Namespace: "team-a"},
+		Data:       map[string][]byte{"password": []byte("hunter2")},
+	}
+	scheme := runtime.NewScheme()
+	if err := corev1.AddToScheme(scheme); err != nil {
+		t.Fatal(err)
+	}
+	mapper := meta.NewDefaultRESTMapper(nil)
+	mapper.Add(schema.GroupVersionKind{Version: "v1", Kind: "Secret"}, meta.RESTScopeNamespace)
+	cl := &cluster{dynamic: dynamicfake.NewSimpleDynamicClient(scheme, secret), mapper: mapper}
+
+	text := "<@U1> k diff ```\napiVersion: v1\nkind: Secret\nmetadata:\n  name: db\n  namespace: team-a\ndata:\n  password: eA==\n```"
+	ctx := withMessageText(withCluster(context.Background(), cl), text)
+	reply, err := diffCommand{}.Handle(ctx, map[string]string{"manifest": text}, fake.NewSimpleClientset())
+	if err != nil {
+		t.Fatal(err)
+	}
+	// The live value, base64 encoded or not, must never make it into the reply
+	for _, live := range []string{"hunter2", "aHVudGVyMg=="} {
+		if strings.Contains(reply, live) {
+			t.Errorf("reply %q leaks the live secret value %q", reply, live)
+		}
+	}
+	if !strings.Contains(reply, "can't be diffed") {
+		t.Errorf("reply %q doesn't refuse to diff the secret", reply)
+	}
+}
@@ -0,0 +1,54 @@
+package main
+
+import (
+	"log/slog"
+
+	"k8s.io/client-go/discovery"
+)
+
+// legacyAPIs records which resources a cluster only serves from an older API version. The zero value means the
+// cluster serves the current version of everything, which is also what we assume when discovery fails.
+type legacyAPIs struct {
+	// ingress is set when Ingress is only served by networking.k8s.io/v1beta1, i.e. before Kubernetes 1.19
+	ingress bool
+	// cronJob is set when CronJob is only served by batch/v1beta1, i.e. before Kubernetes 1.21
+	cronJob bool
+	// hpa is set when HorizontalPodAutoscaler isn't served by autoscaling/v2, i.e. before Kubernetes 1.23, so only
+	// autoscaling/v1 can be relied on
+	hpa bool
+	// endpointSlice is set when EndpointSlice isn't served by discovery.k8s.io/v1, i.e. before Kubernetes 1.21, so
+	// only core Endpoints can be relied on
+	endpointSlice bool
+}
+
+// discoverAPIs works out which API versions each cluster serves so commands can talk to older clusters
+func (c *clusters) discoverAPIs() {
+	for name, cl := range c.byName {
+		d := cl.clientset.Discovery()
+		cl.legacy.ingress = !servesResource(d, "networking.k8s.io/v1", "ingresses") &&
+			servesResource(d, "networking.k8s.io/v1beta1", "ingresses")
+		cl.legacy.cronJob = !servesResource(d, "batch/v1", "cronjobs") && servesResource(d, "batch/v1beta1", "cronjobs")
+		cl.legacy.hpa = !servesResource(d, "autoscaling/v2", "horizontalpodautoscalers") &&
+			servesResource(d, "autoscaling/v1", "horizontalpodautoscalers")
+		cl.legacy.endpointSlice = !servesResource(d, "discovery.k8s.io/v1", "endpointslices") &&
+			servesResource(d, "v1", "endpoints")
+		slog.Debug("discovered API versions", "context", name,
+			"legacyIngress", cl.legacy.ingress, "legacyCronJob", cl.legacy.cronJob, "legacyHPA", cl.legacy.hpa,
+			"legacyEndpointSlice", cl.legacy.endpointSlice)
+	}
+}
+
+// servesResource reports whether the API server serves resource in groupVersion, treating errors as not served
+func servesResource(d discovery.DiscoveryInterface, groupVersion, resource string) bool {
+	resources, err := d.ServerResourcesForGroupVersion(groupVersion)
+	if err != nil {
+		return false
+	}
+	for _, r := range resources.APIResources {
+		if r.Name == resource {
+			return true
+		}
+	}
+
+	return false
+}
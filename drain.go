@@ -0,0 +1,158 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"golang.org/x/sync/errgroup"
+	corev1 "k8s.io/api/core/v1"
+	policyv1 "k8s.io/api/policy/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes"
+)
+
+// drainConcurrency is how many of a node's pods drain evicts at once
+const drainConcurrency = 10
+
+// cordonCommand marks a node unschedulable or schedulable again, i.e. kubectl cordon and kubectl uncordon
+type cordonCommand struct {
+	mutatingCommand
+}
+
+func (cordonCommand) ConfirmationPrompt(args map[string]string) string {
+	return fmt.Sprintf("%s node `%s`", args["verb"], args["node"])
+}
+
+func (cordonCommand) Handle(ctx context.Context, args map[string]string, clientset kubernetes.Interface) (string, error) {
+	if err := setUnschedulable(ctx, clientset, args["node"], args["verb"] == "cordon"); err != nil {
+		return "", err
+	}
+
+	return fmt.Sprintf("node/%s %sed", args["node"], args["verb"]), nil
+}
+
+// AdminOnly restricts cordoning to ADMIN_USERS, since it affects every workload that could land on the node
+func (cordonCommand) AdminOnly() {}
+
+// setUnschedulable patches node's spec.unschedulable
+func setUnschedulable(ctx context.Context, clientset kubernetes.Interface, node string, unschedulable bool) error {
+	patch := fmt.Sprintf(`{"spec":{"unschedulable":%t}}`, unschedulable)
+	if _, err := clientset.CoreV1().Nodes().Patch(ctx, node, types.StrategicMergePatchType, []byte(patch), metav1.PatchOptions{}); err != nil {
+		if apierrors.IsNotFound(err) {
+			return fmt.Errorf("node `%s` not found", node)
+		}
+		return fmt.Errorf("failed to update node `%s`: %w", node, err)
+	}
+
+	return nil
+}
+
+// drainCommand cordons a node and evicts its pods so they're rescheduled elsewhere, like a lightweight kubectl
+// drain. DaemonSet and mirror pods are left alone since evicting them wouldn't move them, and nothing waits for
+// the evicted pods to terminate.
+type drainCommand struct {
+	mutatingCommand
+}
+
+func (drainCommand) ConfirmationPrompt(args map[string]string) string {
+	return fmt.Sprintf("cordon node `%s` and evict its pods", args["node"])
+}
+
+func (drainCommand) Handle(ctx context.Context, args map[string]string, clientset kubernetes.Interface) (string, error) {
+	deleteOptions := &metav1.DeleteOptions{}
+	if args["gracePeriod"] != "" {
+		seconds, err := strconv.ParseInt(args["gracePeriod"], 10, 64)
+		if err != nil {
+			return "", fmt.Errorf("invalid `--grace-period=%s`, must be a whole number of seconds", args["gracePeriod"])
+		}
+		// Like kubectl, a negative grace period means each pod's own
+		if seconds >= 0 {
+			deleteOptions.GracePeriodSeconds = &seconds
+		}
+	}
+
+	if err := setUnschedulable(ctx, clientset, args["node"], true); err != nil {
+		return "", err
+	}
+	pods, err := clientset.CoreV1().Pods("").List(ctx, metav1.ListOptions{
+		FieldSelector: fields.OneTermEqualSelector("spec.nodeName", args["node"]).String(),
+	})
+	if err != nil {
+		return "", fmt.Errorf("cordoned node `%s` but failed to list its pods: %w", args["node"], err)
+	}
+
+	// Evictions go out a few at a time, since one after another a busy node would outlast API_TIMEOUT
+	var mu sync.Mutex
+	evicted, skipped := 0, 0
+	var failures []string
+	var g errgroup.Group
+	g.SetLimit(drainConcurrency)
+	for _, po := range pods.Items {
+		if !evictable(po) {
+			continue
+		}
+		if !namespaceVisible(ctx, po.Namespace) {
+			skipped++
+			continue
+		}
+		po := po
+		g.Go(func() error {
+			eviction := &policyv1.Eviction{
+				ObjectMeta:    metav1.ObjectMeta{Name: po.Name, Namespace: po.Namespace},
+				DeleteOptions: deleteOptions,
+			}
+			err := clientset.PolicyV1().Evictions(po.Namespace).Evict(ctx, eviction)
+
+			mu.Lock()
+			defer mu.Unlock()
+			switch {
+			case err == nil:
+				evicted++
+			case apierrors.IsNotFound(err):
+				// Gone already, which is what we wanted
+			case apierrors.IsTooManyRequests(err):
+				failures = append(failures, fmt.Sprintf("`%s/%s` is protected by a PodDisruptionBudget right now", po.Namespace, po.Name))
+			default:
+				failures = append(failures, fmt.Sprintf("`%s/%s`: %v", po.Namespace, po.Name, err))
+			}
+			return nil
+		})
+	}
+	g.Wait()
+	sort.Strings(failures)
+
+	reply := fmt.Sprintf("node/%s cordoned, evicted %d pods", args["node"], evicted)
+	if skipped > 0 {
+		reply += fmt.Sprintf(", left %d in namespaces not accessible via mibot", skipped)
+	}
+	if len(failures) > 0 {
+		reply += "\nfailed to evict:\n" + strings.Join(failures, "\n")
+	}
+	return reply, nil
+}
+
+// AdminOnly restricts draining to ADMIN_USERS, since it disrupts every workload on the node
+func (drainCommand) AdminOnly() {}
+
+// evictable reports whether po should be evicted when draining its node, skipping pods that have finished and
+// those evicting wouldn't move: DaemonSet pods come straight back and mirror pods are run by the kubelet itself
+func evictable(po corev1.Pod) bool {
+	if po.Status.Phase == corev1.PodSucceeded || po.Status.Phase == corev1.PodFailed {
+		return false
+	}
+	if _, ok := po.Annotations[corev1.MirrorPodAnnotationKey]; ok {
+		return false
+	}
+	if owner := metav1.GetControllerOf(&po); owner != nil && owner.Kind == "DaemonSet" {
+		return false
+	}
+
+	return true
+}
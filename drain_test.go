@@ -0,0 +1,59 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func TestDrainEvictsPods(t *testing.T) {
+	objects := []runtime.Object{&corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: "node-1"}}}
+	for i := 0; i < 25; i++ {
+		objects = append(objects, &corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Name: fmt.Sprintf("web-%d", i), Namespace: "team-a"},
+			Spec:       corev1.PodSpec{NodeName: "node-1"},
+			Status:     corev1.PodStatus{Phase: corev1.PodRunning},
+		})
+	}
+	controller := true
+	objects = append(objects, &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: "logging-abc", Namespace: "kube-system", OwnerReferences: []metav1.OwnerReference{
+			{APIVersion: "apps/v1", Kind: "DaemonSet", Name: "logging", Controller: &controller},
+		}},
+		Spec:   corev1.PodSpec{NodeName: "node-1"},
+		Status: corev1.PodStatus{Phase: corev1.PodRunning},
+	})
+	clientset := fake.NewSimpleClientset(objects...)
+
+	reply, err := drainCommand{}.Handle(context.Background(), map[string]string{"node": "node-1"}, clientset)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "node/node-1 cordoned, evicted 25 pods"; reply != want {
+		t.Errorf("reply = %q, want %q", reply, want)
+	}
+	node, err := clientset.CoreV1().Nodes().Get(context.Background(), "node-1", metav1.GetOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !node.Spec.Unschedulable {
+		t.Error("node wasn't cordoned")
+	}
+}
+
+func TestDrainRespectsOpenBreaker(t *testing.T) {
+	br := &breaker{failures: breakerThreshold, openUntil: time.Now().Add(breakerCooldown)}
+	b := &bot{clusters: &clusters{byName: map[string]*cluster{"": {clientset: fake.NewSimpleClientset(), breaker: br}}}}
+
+	_, err := b.run(context.Background(), "U1", drainCommand{}, "", map[string]string{"node": "node-1"})
+	if err == nil || !strings.Contains(err.Error(), "currently unavailable") {
+		t.Errorf("run = %v, want the open breaker to stop the drain", err)
+	}
+}
@@ -0,0 +1,68 @@
+package main
+
+import (
+	"context"
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+	discoveryv1 "k8s.io/api/discovery/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+// getEndpointsCommand lists the addresses backing a Service, i.e. kubectl get endpoints $service
+type getEndpointsCommand struct {
+	regexpCommand
+}
+
+func (getEndpointsCommand) Handle(ctx context.Context, args map[string]string, clientset kubernetes.Interface) (string, error) {
+	var rows [][]string
+	if cl := clusterFrom(ctx); cl != nil && cl.legacy.endpointSlice {
+		endpoints, err := clientset.CoreV1().Endpoints(args["namespace"]).Get(ctx, args["name"], metav1.GetOptions{})
+		if err != nil {
+			return "", fmt.Errorf("failed to get endpoints of service `%s` in `%s`: %w", args["name"], args["namespace"], err)
+		}
+		for _, subset := range endpoints.Subsets {
+			for _, address := range subset.Addresses {
+				rows = append(rows, endpointRow(address.IP, true, address.TargetRef, address.NodeName))
+			}
+			for _, address := range subset.NotReadyAddresses {
+				rows = append(rows, endpointRow(address.IP, false, address.TargetRef, address.NodeName))
+			}
+		}
+	} else {
+		list, err := clientset.DiscoveryV1().EndpointSlices(args["namespace"]).List(ctx, metav1.ListOptions{
+			LabelSelector: discoveryv1.LabelServiceName + "=" + args["name"],
+		})
+		if err != nil {
+			return "", fmt.Errorf("failed to list endpoint slices of service `%s` in `%s`: %w", args["name"], args["namespace"], err)
+		}
+		for _, slice := range list.Items {
+			for _, endpoint := range slice.Endpoints {
+				// A nil ready condition means the endpoint should be treated as ready
+				ready := endpoint.Conditions.Ready == nil || *endpoint.Conditions.Ready
+				for _, address := range endpoint.Addresses {
+					rows = append(rows, endpointRow(address, ready, endpoint.TargetRef, endpoint.NodeName))
+				}
+			}
+		}
+	}
+	if len(rows) == 0 {
+		return fmt.Sprintf("service `%s` has no endpoints, check that its selector matches running pods", args["name"]), nil
+	}
+
+	return renderTable([]string{"ADDRESS", "READY", "TARGET", "NODE"}, rows), nil
+}
+
+// endpointRow renders one endpoint address, the pod or other object behind it and the node it's on
+func endpointRow(address string, ready bool, target *corev1.ObjectReference, nodeName *string) []string {
+	row := []string{address, fmt.Sprint(ready), "<none>", "<none>"}
+	if target != nil {
+		row[2] = target.Kind + "/" + target.Name
+	}
+	if nodeName != nil {
+		row[3] = *nodeName
+	}
+
+	return row
+}
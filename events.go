@@ -0,0 +1,53 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+// eventsLimit is how many of the most recent events get events shows
+const eventsLimit = 20
+
+// getEventsCommand lists the most recent Events in a namespace, i.e. kubectl get events
+type getEventsCommand struct {
+	regexpCommand
+}
+
+func (getEventsCommand) Handle(ctx context.Context, args map[string]string, clientset kubernetes.Interface) (string, error) {
+	listOptions, err := listOptionsFromArgs(args)
+	if err != nil {
+		return "", err
+	}
+	list, err := clientset.CoreV1().Events(args["namespace"]).List(ctx, listOptions)
+	if err != nil {
+		return "", fmt.Errorf("failed to list events in `%s`: %w", args["namespace"], err)
+	}
+	if len(list.Items) == 0 {
+		return "No resources found in " + args["namespace"], nil
+	}
+
+	events := list.Items
+	sort.Slice(events, func(i, j int) bool {
+		return events[j].LastTimestamp.Before(&events[i].LastTimestamp)
+	})
+	if len(events) > eventsLimit {
+		events = events[:eventsLimit]
+	}
+
+	rows := make([][]string, 0, len(events))
+	for _, e := range events {
+		eventType := e.Type
+		if eventType == corev1.EventTypeWarning {
+			eventType = "⚠️ " + eventType
+		}
+		object := strings.ToLower(e.InvolvedObject.Kind) + "/" + e.InvolvedObject.Name
+		rows = append(rows, []string{eventType, e.Reason, object, e.Message})
+	}
+
+	return renderSortedTable(args, []string{"TYPE", "REASON", "OBJECT", "MESSAGE"}, rows)
+}
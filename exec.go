@@ -0,0 +1,114 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/tools/remotecommand"
+)
+
+// execOutputLimit is the most output exec keeps from a command, which is plenty for a config file
+const execOutputLimit = 1 << 20
+
+// execAllowedCommands are the commands exec may run, from EXEC_ALLOWED_CMDS. Each is either a program, e.g.
+// cat, which may be run with any arguments, or a whole command line, e.g. cat /etc/config, which must match
+// exactly. Exec is disabled while it's empty.
+var execAllowedCommands []string
+
+// execCommand runs a single allowlisted command in a container and uploads what it printed, i.e. kubectl exec.
+// It's treated as mutating, since there's no telling what a command does inside a container.
+type execCommand struct {
+	mutatingCommand
+}
+
+func (execCommand) Handle(ctx context.Context, args map[string]string, clientset kubernetes.Interface) (string, error) {
+	// No shell is involved, so arguments are split on whitespace and nothing is expanded
+	command := strings.Fields(args["command"])
+	if !execAllowed(command) {
+		if len(execAllowedCommands) == 0 {
+			return "", errors.New("exec is disabled, set EXEC_ALLOWED_CMDS to the commands it may run")
+		}
+		return "", fmt.Errorf("`%s` isn't allowed, exec may only run: %s", args["command"], strings.Join(execAllowedCommands, ", "))
+	}
+	cl := clusterFrom(ctx)
+	if cl == nil {
+		return "", errors.New("no cluster to exec in")
+	}
+
+	req := clientset.CoreV1().RESTClient().Post().
+		Resource("pods").
+		Namespace(args["namespace"]).
+		Name(args["pod"]).
+		SubResource("exec").
+		VersionedParams(&corev1.PodExecOptions{
+			Container: args["container"],
+			Command:   command,
+			Stdout:    true,
+			Stderr:    true,
+		}, scheme.ParameterCodec)
+	executor, err := remotecommand.NewSPDYExecutor(cl.config, "POST", req.URL())
+	if err != nil {
+		return "", fmt.Errorf("failed to exec in pod `%s` in `%s`: %w", args["pod"], args["namespace"], err)
+	}
+
+	// stdout and stderr share one buffer so they interleave the way they would in a terminal
+	output := &limitedBuffer{limit: execOutputLimit}
+	err = executor.StreamWithContext(ctx, remotecommand.StreamOptions{Stdout: output, Stderr: output})
+	if err != nil && output.Len() == 0 {
+		return "", fmt.Errorf("failed to exec `%s` in pod `%s` in `%s`: %w", args["command"], args["pod"], args["namespace"], err)
+	}
+
+	reply := output.String()
+	if output.truncated {
+		reply += fmt.Sprintf("\n[output truncated at %d bytes]", execOutputLimit)
+	}
+	if err != nil {
+		// A non-zero exit still printed something worth seeing
+		reply += "\n[" + err.Error() + "]"
+	}
+	if reply == "" {
+		reply = "[no output]"
+	}
+	return reply, nil
+}
+
+func (execCommand) Snippet(args map[string]string) (string, string) {
+	return args["pod"] + ".txt", "text"
+}
+
+// execAllowed reports whether command is in execAllowedCommands
+func execAllowed(command []string) bool {
+	if len(command) == 0 {
+		return false
+	}
+	for _, allowed := range execAllowedCommands {
+		if allowed == command[0] || allowed == strings.Join(command, " ") {
+			return true
+		}
+	}
+
+	return false
+}
+
+// limitedBuffer keeps the first limit bytes written to it and quietly drops the rest
+type limitedBuffer struct {
+	bytes.Buffer
+	limit     int
+	truncated bool
+}
+
+func (b *limitedBuffer) Write(p []byte) (int, error) {
+	if room := b.limit - b.Len(); len(p) > room {
+		b.truncated = true
+		b.Buffer.Write(p[:max(room, 0)])
+		return len(p), nil
+	}
+
+	return b.Buffer.Write(p)
+}
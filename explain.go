@@ -0,0 +1,161 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"k8s.io/client-go/kubernetes"
+)
+
+// explanation describes a resource and the columns mibot shows for it, beyond NAME, NAMESPACE and AGE which mean
+// the same everywhere unless it says otherwise
+type explanation struct {
+	description string
+	// columns are pairs of a column and what it shows, in the order the columns appear
+	columns [][2]string
+}
+
+// explanations are keyed by the commandName of the get command that lists the resource, so explain finds them
+// through the command registry and its aliases
+var explanations = map[string]explanation{
+	"getPod": {"The smallest deployable unit, one or more containers scheduled together on a node.", [][2]string{
+		{"READY", "containers passing their readiness probe out of all containers"},
+		{"STATUS", "why a container is stuck, e.g. CrashLoopBackOff, or else the pod's phase"},
+		{"RESTARTS", "container restarts, summed over the pod's containers"},
+		{"IP", "with -o wide, the pod's IP address in the cluster network"},
+		{"NODE", "with -o wide, the node the pod is scheduled on"},
+		{"NOMINATED NODE", "with -o wide, the node the scheduler is making room on by preempting other pods"},
+	}},
+	"getDeploy": {"Keeps a number of identical pods running and rolls them out gradually when their template changes.", [][2]string{
+		{"READY", "ready replicas out of the desired replicas"},
+		{"UP-TO-DATE", "replicas running the latest pod template"},
+		{"AVAILABLE", "replicas that have been ready for at least minReadySeconds"},
+		{"AGE", "how long ago the deployment was created, not when it last rolled out"},
+		{"IMAGES", "with -o wide, the image of each container in the pod template"},
+	}},
+	"getStatefulSet": {"Runs pods with stable names and storage, started and updated one at a time.", [][2]string{
+		{"READY", "ready replicas out of the desired replicas"},
+	}},
+	"getDaemonSet": {"Runs a copy of a pod on every node, or every node matching its selector.", [][2]string{
+		{"READY", "nodes with a ready pod out of the nodes that should run one"},
+	}},
+	"getReplicaSet": {"Keeps a number of identical pods running, usually managed by a deployment.", [][2]string{
+		{"READY", "ready replicas out of the desired replicas"},
+	}},
+	"getJobs": {"Runs pods until a number of them complete successfully.", [][2]string{
+		{"COMPLETIONS", "pods that succeeded out of the completions wanted"},
+		{"DURATION", "how long the job ran, or has been running"},
+	}},
+	"getCronJobs": {"Creates jobs on a schedule.", [][2]string{
+		{"SCHEDULE", "when jobs are created, in cron syntax"},
+		{"SUSPEND", "whether new jobs are held back"},
+		{"LAST SCHEDULE", "how long ago the last job was created"},
+	}},
+	"getHPA": {"Scales a workload's replicas to keep a metric, usually CPU, near a target.", [][2]string{
+		{"REFERENCE", "the workload being scaled"},
+		{"TARGETS", "current utilization against the target"},
+		{"MINPODS", "the fewest replicas it scales down to"},
+		{"MAXPODS", "the most replicas it scales up to"},
+		{"REPLICAS", "the replicas it currently runs"},
+	}},
+	"getSvc": {"A stable address load balancing over the pods matching its selector.", [][2]string{
+		{"TYPE", "how it's exposed: ClusterIP inside the cluster, NodePort or LoadBalancer outside it"},
+		{"CLUSTER-IP", "the virtual IP other pods reach it on"},
+		{"PORT(S)", "its ports, with the node port after a colon if it has one"},
+	}},
+	"getEndpoints": {"The pod addresses a service currently sends traffic to.", [][2]string{
+		{"ADDRESS", "the IP traffic goes to"},
+		{"READY", "whether the address receives traffic, i.e. its pod is ready"},
+		{"TARGET", "the pod, or other object, behind the address"},
+		{"NODE", "the node the address is on"},
+	}},
+	"getIngress": {"Routes HTTP traffic from outside the cluster to services by host and path.", [][2]string{
+		{"CLASS", "the ingress controller that serves it"},
+		{"HOSTS", "the hostnames it routes"},
+		{"ADDRESS", "where the controller exposes it"},
+	}},
+	"getPVC": {"A request for storage, bound to a persistent volume that satisfies it.", [][2]string{
+		{"STATUS", "Bound once a volume is found for it, otherwise Pending"},
+		{"VOLUME", "the persistent volume it's bound to"},
+		{"CAPACITY", "the size of that volume"},
+		{"STORAGECLASS", "the kind of storage it asked for"},
+	}},
+	"getPV": {"A piece of storage in the cluster, provisioned by an admin or a storage class.", [][2]string{
+		{"STATUS", "Available, Bound to a claim, or Released once that claim is deleted"},
+		{"CLAIM", "the claim it's bound to"},
+		{"CAPACITY", "its size"},
+		{"STORAGECLASS", "the kind of storage it is"},
+	}},
+	"getConfigMaps": {"Non-secret configuration for pods, as keys and values.", [][2]string{
+		{"DATA", "how many keys it holds"},
+	}},
+	"getSecrets": {"Sensitive configuration for pods like passwords and tokens. mibot never shows their values.", [][2]string{
+		{"TYPE", "what the secret holds, e.g. kubernetes.io/tls"},
+		{"DATA", "how many keys it holds"},
+	}},
+	"getEvents": {"Reports of things that happened to objects, kept for about an hour.", [][2]string{
+		{"TYPE", "Normal, or Warning when something went wrong"},
+		{"REASON", "a short machine-readable cause"},
+		{"OBJECT", "the object it happened to"},
+		{"MESSAGE", "what happened"},
+	}},
+	"getNamespaces": {"Divides the cluster's objects between teams or applications.", [][2]string{
+		{"STATUS", "Active, or Terminating while it's being deleted"},
+	}},
+	"getNodes": {"A machine pods are scheduled onto.", [][2]string{
+		{"STATUS", "Ready when its kubelet is healthy, plus SchedulingDisabled when cordoned"},
+		{"ROLES", "its node-role.kubernetes.io labels"},
+		{"VERSION", "its kubelet version"},
+	}},
+}
+
+// explainCommand describes a resource and the columns mibot shows for it, i.e. kubectl explain
+type explainCommand struct {
+	regexpCommand
+}
+
+func (explainCommand) Handle(ctx context.Context, args map[string]string, clientset kubernetes.Interface) (string, error) {
+	resource := args["resource"]
+	if resource == "" {
+		return "explain one of: " + strings.Join(explainableResources(), ", "), nil
+	}
+
+	// Resolve aliases like sts or statefulsets the same way get does, some of which need a name to match
+	prefix := commandPrefixes[0] + " get " + resource
+	cmd := findCommand(prefix)
+	if cmd == nil {
+		cmd = findCommand(prefix + " $name")
+	}
+	if _, ok := cmd.(getResourceCommand); ok || cmd == nil {
+		return fmt.Sprintf("mibot doesn't know about `%s`, try one of: %s", resource, strings.Join(explainableResources(), ", ")), nil
+	}
+
+	e, ok := explanations[commandName(cmd)]
+	if !ok {
+		// Configured commands have no curated explanation, but do have a description
+		return fmt.Sprintf("*%s*: %s\nNAME and AGE are each object's name and how long ago it was created.", resource, cmd.Description()), nil
+	}
+	var b strings.Builder
+	b.WriteString("*" + resource + "*: " + e.description + "\n")
+	for _, column := range e.columns {
+		b.WriteString("• `" + column[0] + "` " + column[1] + "\n")
+	}
+	b.WriteString("NAME and AGE are each object's name and how long ago it was created, NAMESPACE where it lives.")
+
+	return b.String(), nil
+}
+
+// explainableResources lists the resource names get commands are registered under, e.g. sts
+func explainableResources() []string {
+	var resources []string
+	for _, cmd := range commands {
+		if words := commandWords(cmd.Usage()); len(words) == 3 && words[1] == "get" && words[2] != "all" {
+			resources = append(resources, words[2])
+		}
+	}
+	sort.Strings(resources)
+
+	return resources
+}
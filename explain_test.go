@@ -0,0 +1,24 @@
+package main
+
+import (
+	"context"
+	"strings"
+	"testing"
+)
+
+func TestExplainColumns(t *testing.T) {
+	for resource, columns := range map[string][]string{
+		"pods":   {"READY", "STATUS", "RESTARTS", "IP", "NODE", "NOMINATED NODE"},
+		"deploy": {"READY", "UP-TO-DATE", "AVAILABLE", "AGE", "IMAGES"},
+	} {
+		reply, err := explainCommand{}.Handle(context.Background(), map[string]string{"resource": resource}, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, column := range columns {
+			if !strings.Contains(reply, "`"+column+"`") {
+				t.Errorf("explain %s doesn't describe %s: %q", resource, column, reply)
+			}
+		}
+	}
+}
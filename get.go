@@ -0,0 +1,494 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/client-go/kubernetes"
+)
+
+// getDeployCommand lists Deployments, i.e. kubectl get deploy
+type getDeployCommand struct {
+	regexpCommand
+}
+
+func (getDeployCommand) Handle(ctx context.Context, args map[string]string, clientset kubernetes.Interface) (string, error) {
+	listOptions, err := listOptionsFromArgs(args)
+	if err != nil {
+		return "", err
+	}
+	deployments, err := listDeployments(ctx, clientset, args["namespace"], listOptions)
+	if err != nil {
+		return "", fmt.Errorf("failed to list deployments in %s: %w", namespaceScope(args["namespace"]), err)
+	}
+	wide := args["wide"] != ""
+	args = withoutFlags(args, "wide")
+
+	header := []string{"NAME", "READY", "UP-TO-DATE", "AVAILABLE", "AGE"}
+	if wide {
+		header = append(header, "IMAGES")
+	}
+	if args["allNamespaces"] != "" {
+		header = append([]string{"NAMESPACE"}, header...)
+	}
+	rows := make([][]string, 0, len(deployments))
+	for _, d := range deployments {
+		// Like kubectl, readiness is out of the replicas asked for rather than those that exist
+		desired := int32(1)
+		if d.Spec.Replicas != nil {
+			desired = *d.Spec.Replicas
+		}
+		row := []string{
+			d.Name,
+			readyOf(d.Status.ReadyReplicas, desired),
+			strconv.Itoa(int(d.Status.UpdatedReplicas)),
+			strconv.Itoa(int(d.Status.AvailableReplicas)),
+			age(d.CreationTimestamp),
+		}
+		if wide {
+			row = append(row, containerImages(d.Spec.Template.Spec))
+		}
+		if args["allNamespaces"] != "" {
+			row = append([]string{d.Namespace}, row...)
+		}
+		rows = append(rows, row)
+	}
+
+	return renderSortedTable(args, header, rows)
+}
+
+// getPodCommand lists Pods, i.e. kubectl get pods
+type getPodCommand struct {
+	regexpCommand
+}
+
+func (getPodCommand) Handle(ctx context.Context, args map[string]string, clientset kubernetes.Interface) (string, error) {
+	listOptions, err := listOptionsFromArgs(args)
+	if err != nil {
+		return "", err
+	}
+	pods, err := listPods(ctx, clientset, args["namespace"], listOptions)
+	if err != nil {
+		return "", fmt.Errorf("failed to list pods in %s: %w", namespaceScope(args["namespace"]), err)
+	}
+	// Grep first, so the problems summary only counts the pods asked about
+	if term := args["grep"]; term != "" {
+		if pods = grepPods(pods, term); len(pods) == 0 {
+			return noMatches(term), nil
+		}
+	}
+	var healthy string
+	if args["problems"] != "" {
+		pods, healthy = podProblems(pods)
+	}
+	wide := args["wide"] != ""
+	args = withoutFlags(args, "problems", "wide", "grep")
+
+	header := []string{"NAME", "READY", "STATUS", "RESTARTS", "AGE"}
+	if wide {
+		header = append(header, "IP", "NODE", "NOMINATED NODE")
+	}
+	if args["allNamespaces"] != "" {
+		header = append([]string{"NAMESPACE"}, header...)
+	}
+	if statusEmoji {
+		header = append([]string{""}, header...)
+	}
+	rows := make([][]string, 0, len(pods))
+	for _, po := range pods {
+		ready, restarts := podReadiness(po)
+		row := []string{po.Name, ready, podStatus(po), strconv.Itoa(restarts), age(po.CreationTimestamp)}
+		if wide {
+			row = append(row, orNone(po.Status.PodIP), orNone(po.Spec.NodeName), orNone(po.Status.NominatedNodeName))
+		}
+		if args["allNamespaces"] != "" {
+			row = append([]string{po.Namespace}, row...)
+		}
+		if statusEmoji {
+			row = append([]string{podEmoji(po)}, row...)
+		}
+		rows = append(rows, row)
+	}
+	if len(rows) == 0 && healthy != "" {
+		return "no problems, " + healthy, nil
+	}
+
+	reply, err := renderSortedTable(args, header, rows)
+	if err != nil || healthy == "" {
+		return reply, err
+	}
+	return reply + "\n" + healthy, nil
+}
+
+// grepPods returns the pods whose names contain term, ignoring case
+func grepPods(pods []corev1.Pod, term string) []corev1.Pod {
+	matched := make([]corev1.Pod, 0, len(pods))
+	for _, po := range pods {
+		if nameContains(po.Name, term) {
+			matched = append(matched, po)
+		}
+	}
+	return matched
+}
+
+// statusEmoji starts each pod's line with an emoji for its health, unless STATUS_EMOJI is false
+var statusEmoji = true
+
+// failingStatuses are the pod statuses, or init container statuses after Init:, that mean something is broken
+// rather than still starting
+var failingStatuses = map[string]bool{
+	"CrashLoopBackOff":           true,
+	"ImagePullBackOff":           true,
+	"ErrImagePull":               true,
+	"InvalidImageName":           true,
+	"CreateContainerConfigError": true,
+	"CreateContainerError":       true,
+	"Error":                      true,
+	"OOMKilled":                  true,
+	"Evicted":                    true,
+	"Failed":                     true,
+}
+
+// podEmoji sums up a pod's health for scanning a long list: 🟢 running and ready or completed, 🔴 failing,
+// ⚪ unknown and 🟡 anything in between, e.g. pending
+func podEmoji(po corev1.Pod) string {
+	status := strings.TrimPrefix(podStatus(po), "Init:")
+	allReady := len(po.Status.ContainerStatuses) > 0
+	for _, container := range po.Status.ContainerStatuses {
+		allReady = allReady && container.Ready
+	}
+
+	switch {
+	case failingStatuses[status] || strings.HasPrefix(status, "ExitCode:") || strings.HasPrefix(status, "Signal:"):
+		return "🔴"
+	case status == "Running" && allReady, status == "Completed", status == string(corev1.PodSucceeded):
+		return "🟢"
+	case status == string(corev1.PodUnknown):
+		return "⚪"
+	default:
+		return "🟡"
+	}
+}
+
+// unhealthyRestarts is how many restarts make an otherwise healthy pod a problem for --problems
+const unhealthyRestarts = 3
+
+// podProblems splits out the pods that are unhealthy, i.e. not running with every container ready, or restarting
+// more than unhealthyRestarts times, summarizing the rest like +12 Running, +3 Completed
+func podProblems(pods []corev1.Pod) ([]corev1.Pod, string) {
+	var problems []corev1.Pod
+	healthy := make(map[string]int)
+	var statuses []string
+	for _, po := range pods {
+		status := podStatus(po)
+		_, restarts := podReadiness(po)
+		allReady := len(po.Status.ContainerStatuses) > 0
+		for _, container := range po.Status.ContainerStatuses {
+			allReady = allReady && container.Ready
+		}
+		if (status == "Running" && allReady || status == "Completed" || po.Status.Phase == corev1.PodSucceeded) && restarts <= unhealthyRestarts {
+			if healthy[status] == 0 {
+				statuses = append(statuses, status)
+			}
+			healthy[status]++
+			continue
+		}
+		problems = append(problems, po)
+	}
+
+	summary := make([]string, 0, len(statuses))
+	for _, status := range statuses {
+		summary = append(summary, fmt.Sprintf("+%d %s", healthy[status], status))
+	}
+	return problems, strings.Join(summary, ", ")
+}
+
+// withoutFlags returns a copy of args with flags dealt with, so renderSortedTable doesn't refuse them
+func withoutFlags(args map[string]string, flags ...string) map[string]string {
+	copied := make(map[string]string, len(args))
+	for k, v := range args {
+		copied[k] = v
+	}
+	for _, flag := range flags {
+		copied[flag] = ""
+	}
+
+	return copied
+}
+
+// containerImages renders the images of a pod template's containers, comma separated like kubectl
+func containerImages(spec corev1.PodSpec) string {
+	images := make([]string, 0, len(spec.Containers))
+	for _, container := range spec.Containers {
+		images = append(images, container.Image)
+	}
+
+	return strings.Join(images, ",")
+}
+
+// orNone renders an optional field the way kubectl does, as <none> when it's empty
+func orNone(value string) string {
+	if value == "" {
+		return "<none>"
+	}
+
+	return value
+}
+
+// podReadiness renders how many of a pod's containers are ready, e.g. 1/2, and counts their restarts
+func podReadiness(po corev1.Pod) (string, int) {
+	readyContainers, restarts := 0, 0
+	for _, container := range po.Status.ContainerStatuses {
+		if container.Ready {
+			readyContainers++
+		}
+		restarts += int(container.RestartCount)
+	}
+
+	return strconv.Itoa(readyContainers) + "/" + strconv.Itoa(len(po.Status.ContainerStatuses)), restarts
+}
+
+// podStatus is a pod's STATUS the way kubectl shows it, preferring why a container is stuck, e.g.
+// CrashLoopBackOff, over the pod's phase, which stays Running while its containers restart
+func podStatus(po corev1.Pod) string {
+	status := string(po.Status.Phase)
+	if po.Status.Reason != "" {
+		status = po.Status.Reason
+	}
+
+	for i, container := range po.Status.InitContainerStatuses {
+		switch {
+		case container.State.Terminated != nil && container.State.Terminated.ExitCode == 0:
+			continue
+		case container.State.Terminated != nil && container.State.Terminated.Reason != "":
+			return "Init:" + container.State.Terminated.Reason
+		case container.State.Terminated != nil:
+			return "Init:ExitCode:" + strconv.Itoa(int(container.State.Terminated.ExitCode))
+		case container.State.Waiting != nil && container.State.Waiting.Reason != "" && container.State.Waiting.Reason != "PodInitializing":
+			return "Init:" + container.State.Waiting.Reason
+		default:
+			return "Init:" + strconv.Itoa(i) + "/" + strconv.Itoa(len(po.Spec.InitContainers))
+		}
+	}
+
+	// Like kubectl, the last container with something to say wins
+	for _, container := range po.Status.ContainerStatuses {
+		switch {
+		case container.State.Waiting != nil && container.State.Waiting.Reason != "":
+			status = container.State.Waiting.Reason
+		case container.State.Terminated != nil && container.State.Terminated.Reason != "":
+			status = container.State.Terminated.Reason
+		case container.State.Terminated != nil:
+			status = "ExitCode:" + strconv.Itoa(int(container.State.Terminated.ExitCode))
+		}
+	}
+
+	if po.DeletionTimestamp != nil {
+		return "Terminating"
+	}
+	return status
+}
+
+// getSvcCommand lists Services, i.e. kubectl get svc
+type getSvcCommand struct {
+	regexpCommand
+}
+
+func (getSvcCommand) Handle(ctx context.Context, args map[string]string, clientset kubernetes.Interface) (string, error) {
+	listOptions, err := listOptionsFromArgs(args)
+	if err != nil {
+		return "", err
+	}
+	list, err := clientset.CoreV1().Services(args["namespace"]).List(ctx, listOptions)
+	if err != nil {
+		return "", fmt.Errorf("failed to list services in `%s`: %w", args["namespace"], err)
+	}
+	if len(list.Items) == 0 {
+		return "No resources found in " + args["namespace"], nil
+	}
+
+	rows := make([][]string, 0, len(list.Items))
+	for _, svc := range list.Items {
+		rows = append(rows, []string{svc.Name, string(svc.Spec.Type), svc.Spec.ClusterIP, servicePorts(svc.Spec.Ports)})
+	}
+
+	return renderSortedTable(args, []string{"NAME", "TYPE", "CLUSTER-IP", "PORT(S)"}, rows)
+}
+
+// getNodesCommand lists cluster Nodes, i.e. kubectl get nodes
+type getNodesCommand struct {
+	regexpCommand
+}
+
+func (getNodesCommand) Handle(ctx context.Context, args map[string]string, clientset kubernetes.Interface) (string, error) {
+	listOptions, err := listOptionsFromArgs(args)
+	if err != nil {
+		return "", err
+	}
+	list, err := clientset.CoreV1().Nodes().List(ctx, listOptions)
+	if err != nil {
+		return "", fmt.Errorf("failed to list nodes: %w", err)
+	}
+
+	rows := make([][]string, 0, len(list.Items))
+	for _, node := range list.Items {
+		rows = append(rows, []string{node.Name, nodeStatus(node), nodeRoles(node), node.Status.NodeInfo.KubeletVersion})
+	}
+
+	return renderSortedTable(args, []string{"NAME", "STATUS", "ROLES", "VERSION"}, rows)
+}
+
+// nodeStatus derives Ready/NotReady from a node's Ready condition, like kubectl
+func nodeStatus(node corev1.Node) string {
+	status := "NotReady"
+	for _, condition := range node.Status.Conditions {
+		if condition.Type == corev1.NodeReady && condition.Status == corev1.ConditionTrue {
+			status = "Ready"
+		}
+	}
+	if node.Spec.Unschedulable {
+		status += ",SchedulingDisabled"
+	}
+
+	return status
+}
+
+// nodeRoles collects a node's roles from its node-role.kubernetes.io/* labels
+func nodeRoles(node corev1.Node) string {
+	var roles []string
+	for label := range node.Labels {
+		if role := strings.TrimPrefix(label, "node-role.kubernetes.io/"); role != label && role != "" {
+			roles = append(roles, role)
+		}
+	}
+	if len(roles) == 0 {
+		return "<none>"
+	}
+	sort.Strings(roles)
+
+	return strings.Join(roles, ",")
+}
+
+// listPods lists pods from the informer cache in ctx if there is one, otherwise from the API server
+func listPods(ctx context.Context, clientset kubernetes.Interface, namespace string, listOptions metav1.ListOptions) ([]corev1.Pod, error) {
+	// Listers can only filter by label, so field selectors go to the API server
+	l := listersFrom(ctx)
+	if l == nil || listOptions.FieldSelector != "" {
+		list, err := clientset.CoreV1().Pods(namespace).List(ctx, listOptions)
+		if err != nil {
+			return nil, err
+		}
+		return list.Items, nil
+	}
+
+	selector, err := labels.Parse(listOptions.LabelSelector)
+	if err != nil {
+		return nil, err
+	}
+	var cached []*corev1.Pod
+	if namespace == "" {
+		cached, err = l.pods.List(selector)
+	} else {
+		cached, err = l.pods.Pods(namespace).List(selector)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	pods := make([]corev1.Pod, 0, len(cached))
+	for _, po := range cached {
+		pods = append(pods, *po)
+	}
+	return pods, nil
+}
+
+// listDeployments lists deployments from the informer cache in ctx if there is one, otherwise from the API server
+func listDeployments(ctx context.Context, clientset kubernetes.Interface, namespace string, listOptions metav1.ListOptions) ([]appsv1.Deployment, error) {
+	// Listers can only filter by label, so field selectors go to the API server
+	l := listersFrom(ctx)
+	if l == nil || listOptions.FieldSelector != "" {
+		list, err := clientset.AppsV1().Deployments(namespace).List(ctx, listOptions)
+		if err != nil {
+			return nil, err
+		}
+		return list.Items, nil
+	}
+
+	selector, err := labels.Parse(listOptions.LabelSelector)
+	if err != nil {
+		return nil, err
+	}
+	var cached []*appsv1.Deployment
+	if namespace == "" {
+		cached, err = l.deployments.List(selector)
+	} else {
+		cached, err = l.deployments.Deployments(namespace).List(selector)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	deployments := make([]appsv1.Deployment, 0, len(cached))
+	for _, d := range cached {
+		deployments = append(deployments, *d)
+	}
+	return deployments, nil
+}
+
+// listOptionsFromArgs builds the ListOptions for the flags of a get command, rejecting malformed selectors and
+// sort keys before they reach the API server
+func listOptionsFromArgs(args map[string]string) (metav1.ListOptions, error) {
+	var listOptions metav1.ListOptions
+	if selector := args["selector"]; selector != "" {
+		if _, err := labels.Parse(selector); err != nil {
+			return listOptions, fmt.Errorf("invalid label selector `%s`: %w", selector, err)
+		}
+		listOptions.LabelSelector = selector
+	}
+	if selector := args["fieldSelector"]; selector != "" {
+		if _, err := fields.ParseSelector(selector); err != nil {
+			return listOptions, fmt.Errorf("invalid field selector `%s`, expected e.g. `status.phase=Running`: %w", selector, err)
+		}
+		listOptions.FieldSelector = selector
+	}
+	if err := validateSortBy(args["sortBy"]); err != nil {
+		return listOptions, err
+	}
+
+	return listOptions, nil
+}
+
+// servicePorts renders a Service's ports the way kubectl does, e.g. 80/TCP or 80:30080/TCP
+func servicePorts(ports []corev1.ServicePort) string {
+	if len(ports) == 0 {
+		return "<none>"
+	}
+
+	rendered := make([]string, 0, len(ports))
+	for _, port := range ports {
+		p := strconv.Itoa(int(port.Port))
+		if port.NodePort != 0 {
+			p += ":" + strconv.Itoa(int(port.NodePort))
+		}
+		rendered = append(rendered, p+"/"+string(port.Protocol))
+	}
+
+	return strings.Join(rendered, ",")
+}
+
+// namespaceScope describes the namespace a command ran against for use in replies
+func namespaceScope(namespace string) string {
+	if namespace == "" {
+		return "all namespaces"
+	}
+
+	return "`" + namespace + "`"
+}
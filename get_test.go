@@ -0,0 +1,127 @@
+package main
+
+import (
+	"context"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func TestGetDeployHandle(t *testing.T) {
+	replicas := int32(3)
+	clientset := fake.NewSimpleClientset(&appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "team-a", CreationTimestamp: metav1.NewTime(time.Now().Add(-5 * time.Hour))},
+		Spec:       appsv1.DeploymentSpec{Replicas: &replicas},
+		Status:     appsv1.DeploymentStatus{ReadyReplicas: 2, UpdatedReplicas: 3, AvailableReplicas: 2},
+	})
+
+	reply, err := getDeployCommand{}.Handle(context.Background(), map[string]string{"namespace": "team-a"}, clientset)
+	if err != nil {
+		t.Fatal(err)
+	}
+	header, rows, ok := parseTable(reply)
+	if !ok {
+		t.Fatalf("reply %q isn't a table", reply)
+	}
+	if want := []string{"NAME", "READY", "UP-TO-DATE", "AVAILABLE", "AGE"}; !reflect.DeepEqual(header, want) {
+		t.Errorf("header = %v, want %v", header, want)
+	}
+	if want := [][]string{{"web", "2/3", "3", "2", "5h"}}; !reflect.DeepEqual(rows, want) {
+		t.Errorf("rows = %v, want %v", rows, want)
+	}
+}
+
+func TestGetPodHandle(t *testing.T) {
+	defer func(enabled bool) { statusEmoji = enabled }(statusEmoji)
+	statusEmoji = false
+
+	clientset := fake.NewSimpleClientset(&corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: "web-1", Namespace: "team-a", CreationTimestamp: metav1.NewTime(time.Now().Add(-5 * time.Minute))},
+		Spec:       corev1.PodSpec{Containers: []corev1.Container{{Name: "app"}, {Name: "proxy"}}},
+		Status: corev1.PodStatus{
+			Phase: corev1.PodRunning,
+			ContainerStatuses: []corev1.ContainerStatus{
+				{Name: "app", Ready: true, RestartCount: 1, State: corev1.ContainerState{Running: &corev1.ContainerStateRunning{}}},
+				{Name: "proxy", Ready: false, RestartCount: 2, State: corev1.ContainerState{Running: &corev1.ContainerStateRunning{}}},
+			},
+		},
+	})
+
+	reply, err := getPodCommand{}.Handle(context.Background(), map[string]string{"namespace": "team-a"}, clientset)
+	if err != nil {
+		t.Fatal(err)
+	}
+	header, rows, ok := parseTable(reply)
+	if !ok {
+		t.Fatalf("reply %q isn't a table", reply)
+	}
+	if want := []string{"NAME", "READY", "STATUS", "RESTARTS", "AGE"}; !reflect.DeepEqual(header, want) {
+		t.Errorf("header = %v, want %v", header, want)
+	}
+	if want := [][]string{{"web-1", "1/2", "Running", "3", "5m"}}; !reflect.DeepEqual(rows, want) {
+		t.Errorf("rows = %v, want %v", rows, want)
+	}
+}
+
+func TestPodStatusCrashLoopBackOff(t *testing.T) {
+	po := corev1.Pod{
+		Status: corev1.PodStatus{
+			Phase: corev1.PodRunning,
+			ContainerStatuses: []corev1.ContainerStatus{
+				{Name: "proxy", Ready: true, State: corev1.ContainerState{Running: &corev1.ContainerStateRunning{}}},
+				{
+					Name:                 "app",
+					RestartCount:         4,
+					State:                corev1.ContainerState{Waiting: &corev1.ContainerStateWaiting{Reason: "CrashLoopBackOff"}},
+					LastTerminationState: corev1.ContainerState{Terminated: &corev1.ContainerStateTerminated{ExitCode: 1, Reason: "Error"}},
+				},
+			},
+		},
+	}
+
+	if got := podStatus(po); got != "CrashLoopBackOff" {
+		t.Errorf("podStatus = %q, want CrashLoopBackOff", got)
+	}
+}
+
+func TestGetPodProblemsOnlyCountsGreppedPods(t *testing.T) {
+	defer func(enabled bool) { statusEmoji = enabled }(statusEmoji)
+	statusEmoji = false
+
+	pod := func(name string, healthy bool) *corev1.Pod {
+		status := corev1.ContainerStatus{Name: "app", Ready: true, State: corev1.ContainerState{Running: &corev1.ContainerStateRunning{}}}
+		if !healthy {
+			status = corev1.ContainerStatus{Name: "app", State: corev1.ContainerState{Waiting: &corev1.ContainerStateWaiting{Reason: "CrashLoopBackOff"}}}
+		}
+		return &corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "team-a"},
+			Status:     corev1.PodStatus{Phase: corev1.PodRunning, ContainerStatuses: []corev1.ContainerStatus{status}},
+		}
+	}
+	clientset := fake.NewSimpleClientset(pod("web-1", false), pod("web-2", true), pod("api-1", false), pod("api-2", true), pod("api-3", true))
+
+	for _, tc := range []struct {
+		grep, want string
+	}{
+		{"web", "+1 Running"},
+		{"api-2", "no problems, +1 Running"},
+	} {
+		args := map[string]string{"namespace": "team-a", "problems": "--problems", "grep": tc.grep}
+		reply, err := getPodCommand{}.Handle(context.Background(), args, clientset)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !strings.HasSuffix(reply, tc.want) {
+			t.Errorf("grep %s: reply = %q, want it to end with %q", tc.grep, reply, tc.want)
+		}
+		if strings.Contains(reply, "api-1") && tc.grep != "api-1" {
+			t.Errorf("grep %s: reply = %q shows a pod grep filtered out", tc.grep, reply)
+		}
+	}
+}
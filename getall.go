@@ -0,0 +1,55 @@
+package main
+
+import (
+	"context"
+	"strings"
+	"sync"
+
+	"golang.org/x/sync/errgroup"
+	"k8s.io/client-go/kubernetes"
+)
+
+// getAllCommand summarizes the common resources in a namespace, i.e. kubectl get all
+type getAllCommand struct {
+	regexpCommand
+}
+
+// getAllSections are the resources get all lists, in the order they are shown
+var getAllSections = []struct {
+	title string
+	cmd   Command
+}{
+	{"Deployments", getDeployCommand{}},
+	{"Services", getSvcCommand{}},
+	{"Pods", getPodCommand{}},
+}
+
+func (getAllCommand) Handle(ctx context.Context, args map[string]string, clientset kubernetes.Interface) (string, error) {
+	results := make([]string, len(getAllSections))
+	for i, section := range getAllSections {
+		results[i] = "*" + section.title + "*\n" + progressPlaceholder
+	}
+
+	// A failure in one section is reported inline, so none of these return an error
+	var mu sync.Mutex
+	var g errgroup.Group
+	for i, section := range getAllSections {
+		i, section := i, section
+		g.Go(func() error {
+			reply, err := section.cmd.Handle(ctx, args, clientset)
+			if err != nil {
+				reply = "⚠️ " + err.Error()
+			}
+			mu.Lock()
+			defer mu.Unlock()
+			results[i] = "*" + section.title + "*\n" + reply
+			reportProgress(ctx, strings.Join(results, "\n"))
+			return nil
+		})
+	}
+	g.Wait()
+
+	return strings.Join(results, "\n"), nil
+}
+
+func (getAllCommand) ReportsProgress() {}
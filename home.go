@@ -0,0 +1,72 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strconv"
+
+	"github.com/slack-go/slack"
+)
+
+// homeActionPrefix starts the IDs of the quick action buttons on the App Home tab, whose values are command text.
+// Buttons in the same block need IDs of their own, so each has its index appended.
+const homeActionPrefix = "home-"
+
+// publishHome shows user mibot's App Home tab, rebuilt from the command registry every time they open it so it
+// never goes stale. Slack only sends app_home_opened over the Events API, so this is Socket Mode only.
+func (b *bot) publishHome(ctx context.Context, user string) {
+	view := slack.HomeTabViewRequest{Type: slack.VTHomeTab, Blocks: slack.Blocks{BlockSet: b.homeBlocks()}}
+	if _, err := b.api.PublishViewContext(ctx, user, view, ""); err != nil {
+		slog.Error("failed to publish App Home", "user", user, "err", err)
+	}
+}
+
+// homeBlocks introduces mibot with a few quick actions, then lists every registered command
+func (b *bot) homeBlocks() []slack.Block {
+	namespace := b.defaultNamespace
+	if namespace == "" {
+		namespace = "default"
+	}
+	quickActions := []string{
+		fmt.Sprintf("%s get pods -n %s", commandPrefixes[0], namespace),
+		fmt.Sprintf("%s get deploy -n %s", commandPrefixes[0], namespace),
+		fmt.Sprintf("%s get events -n %s", commandPrefixes[0], namespace),
+	}
+	buttons := make([]slack.BlockElement, 0, len(quickActions))
+	for i, text := range quickActions {
+		buttons = append(buttons, slack.NewButtonBlockElement(homeActionPrefix+strconv.Itoa(i), text, slack.NewTextBlockObject(slack.PlainTextType, text, false, false)))
+	}
+
+	blocks := []slack.Block{
+		slack.NewHeaderBlock(slack.NewTextBlockObject(slack.PlainTextType, "mibot", false, false)),
+		slack.NewSectionBlock(slack.NewTextBlockObject(slack.MarkdownType,
+			"Ask me about your Kubernetes clusters by mentioning me in a channel, or message me here. "+
+				"The buttons below run a command and reply in our messages.", false, false), nil, nil),
+		slack.NewActionBlock("", buttons...),
+		slack.NewDividerBlock(),
+	}
+
+	lines := make([]string, 0, len(commands))
+	for _, cmd := range commands {
+		lines = append(lines, fmt.Sprintf("`%s` %s", cmd.Usage(), cmd.Description()))
+	}
+	for i, section := range splitSections("*Commands*", lines) {
+		if i > 0 {
+			section = "…\n" + section
+		}
+		blocks = append(blocks, slack.NewSectionBlock(slack.NewTextBlockObject(slack.MarkdownType, section, false, false), nil, nil))
+	}
+
+	return blocks
+}
+
+// homeChannel returns the channel of user's messages with mibot, which is where App Home quick actions reply
+func (b *bot) homeChannel(user string) (string, error) {
+	channel, _, _, err := b.api.OpenConversation(&slack.OpenConversationParameters{Users: []string{user}})
+	if err != nil {
+		return "", fmt.Errorf("failed to open a conversation with %s: %w", user, err)
+	}
+
+	return channel.ID, nil
+}
@@ -0,0 +1,94 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+
+	autoscalingv2 "k8s.io/api/autoscaling/v2"
+	"k8s.io/client-go/kubernetes"
+)
+
+// getHPACommand lists HorizontalPodAutoscalers, i.e. kubectl get hpa
+type getHPACommand struct {
+	regexpCommand
+}
+
+func (getHPACommand) Handle(ctx context.Context, args map[string]string, clientset kubernetes.Interface) (string, error) {
+	listOptions, err := listOptionsFromArgs(args)
+	if err != nil {
+		return "", err
+	}
+
+	// autoscaling/v1 only knows about CPU, so it has a single target rather than a list of metrics
+	var rows [][]string
+	if cl := clusterFrom(ctx); cl != nil && cl.legacy.hpa {
+		list, err := clientset.AutoscalingV1().HorizontalPodAutoscalers(args["namespace"]).List(ctx, listOptions)
+		if err != nil {
+			return "", fmt.Errorf("failed to list horizontalpodautoscalers in `%s`: %w", args["namespace"], err)
+		}
+		for _, hpa := range list.Items {
+			target := utilization(hpa.Status.CurrentCPUUtilizationPercentage) + "/" + utilization(hpa.Spec.TargetCPUUtilizationPercentage)
+			ref := hpa.Spec.ScaleTargetRef.Kind + "/" + hpa.Spec.ScaleTargetRef.Name
+			rows = append(rows, []string{hpa.Name, ref, target, minReplicas(hpa.Spec.MinReplicas), strconv.Itoa(int(hpa.Spec.MaxReplicas)), strconv.Itoa(int(hpa.Status.CurrentReplicas))})
+		}
+	} else {
+		list, err := clientset.AutoscalingV2().HorizontalPodAutoscalers(args["namespace"]).List(ctx, listOptions)
+		if err != nil {
+			return "", fmt.Errorf("failed to list horizontalpodautoscalers in `%s`: %w", args["namespace"], err)
+		}
+		for _, hpa := range list.Items {
+			ref := hpa.Spec.ScaleTargetRef.Kind + "/" + hpa.Spec.ScaleTargetRef.Name
+			rows = append(rows, []string{hpa.Name, ref, hpaTargets(hpa), minReplicas(hpa.Spec.MinReplicas), strconv.Itoa(int(hpa.Spec.MaxReplicas)), strconv.Itoa(int(hpa.Status.CurrentReplicas))})
+		}
+	}
+	if len(rows) == 0 {
+		return "No resources found in " + args["namespace"], nil
+	}
+
+	return renderSortedTable(args, []string{"NAME", "REFERENCE", "TARGETS", "MINPODS", "MAXPODS", "REPLICAS"}, rows)
+}
+
+// hpaTargets renders the current and target utilization of each of an autoscaler's resource metrics, e.g.
+// 45%/80%, falling back to the metric's name for targets that aren't a utilization
+func hpaTargets(hpa autoscalingv2.HorizontalPodAutoscaler) string {
+	current := make(map[string]*int32)
+	for _, metric := range hpa.Status.CurrentMetrics {
+		if metric.Type == autoscalingv2.ResourceMetricSourceType && metric.Resource != nil {
+			current[string(metric.Resource.Name)] = metric.Resource.Current.AverageUtilization
+		}
+	}
+
+	var targets []string
+	for _, metric := range hpa.Spec.Metrics {
+		if metric.Type != autoscalingv2.ResourceMetricSourceType || metric.Resource == nil || metric.Resource.Target.AverageUtilization == nil {
+			targets = append(targets, strings.ToLower(string(metric.Type)))
+			continue
+		}
+		targets = append(targets, utilization(current[string(metric.Resource.Name)])+"/"+utilization(metric.Resource.Target.AverageUtilization))
+	}
+	if len(targets) == 0 {
+		return "<none>"
+	}
+
+	return strings.Join(targets, ", ")
+}
+
+// utilization renders an optional utilization percentage, which is unknown until metrics have been collected
+func utilization(percent *int32) string {
+	if percent == nil {
+		return "<unknown>"
+	}
+
+	return strconv.Itoa(int(*percent)) + "%"
+}
+
+// minReplicas renders an autoscaler's optional minimum replicas, which defaults to 1
+func minReplicas(min *int32) string {
+	if min == nil {
+		return "1"
+	}
+
+	return strconv.Itoa(int(*min))
+}
@@ -0,0 +1,48 @@
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"k8s.io/client-go/rest"
+	"sigs.k8s.io/yaml"
+)
+
+// impersonation maps Slack user IDs to the Kubernetes usernames their commands run as
+type impersonation map[string]string
+
+// loadImpersonation reads a YAML map of Slack user ID to Kubernetes username from path. An empty path means no
+// one is impersonated.
+func loadImpersonation(path string) (impersonation, error) {
+	if path == "" {
+		return nil, nil
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read impersonation config %q: %w", path, err)
+	}
+	var users impersonation
+	if err := yaml.UnmarshalStrict(data, &users); err != nil {
+		return nil, fmt.Errorf("failed to parse impersonation config %q: %w", path, err)
+	}
+
+	return users, nil
+}
+
+// impersonate returns a copy of cl whose clients act as username, so the API server enforces that user's RBAC
+// and attributes their actions to them in audit logs. The copy has no informer cache since that holds
+// everything the bot itself can see.
+func (cl *cluster) impersonate(username string) (*cluster, error) {
+	config := rest.CopyConfig(cl.config)
+	config.Impersonate = rest.ImpersonationConfig{UserName: username}
+
+	impersonated, err := newClusterClients(cl.name, config)
+	if err != nil {
+		return nil, fmt.Errorf("failed to impersonate %q: %w", username, err)
+	}
+	impersonated.legacy = cl.legacy
+	// Impersonating doesn't make an unreachable cluster any more reachable
+	impersonated.breaker = cl.breaker
+	return impersonated, nil
+}
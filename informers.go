@@ -0,0 +1,60 @@
+package main
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"k8s.io/client-go/informers"
+	appsv1listers "k8s.io/client-go/listers/apps/v1"
+	corev1listers "k8s.io/client-go/listers/core/v1"
+	"k8s.io/client-go/tools/cache"
+)
+
+// informerSyncTimeout is how long startup waits for a cluster's informers to sync before giving up on them
+const informerSyncTimeout = time.Minute
+
+// listers serve Pods and Deployments from a shared informer's in-memory cache instead of the API server
+type listers struct {
+	pods        corev1listers.PodLister
+	deployments appsv1listers.DeploymentLister
+}
+
+// listersFrom returns the informer cache of the cluster carried by ctx, or nil if handlers should list from the
+// API server directly
+func listersFrom(ctx context.Context) *listers {
+	if cl := clusterFrom(ctx); cl != nil {
+		return cl.listers
+	}
+
+	return nil
+}
+
+// startInformers starts Pod and Deployment informers for every cluster and waits up to syncTimeout for them all
+// to sync, the clusters syncing in parallel. A cluster whose informers don't sync in time is logged and keeps
+// using live List calls. It must be called before c is shared.
+func (c *clusters) startInformers(ctx context.Context, syncTimeout time.Duration) {
+	ctx, c.stopInformers = context.WithCancel(ctx)
+	synced := make(map[string][]cache.InformerSynced, len(c.byName))
+	pending := make(map[string]*listers, len(c.byName))
+	for name, cl := range c.byName {
+		factory := informers.NewSharedInformerFactory(cl.clientset, 0)
+		podInformer := factory.Core().V1().Pods()
+		deploymentInformer := factory.Apps().V1().Deployments()
+		pending[name] = &listers{pods: podInformer.Lister(), deployments: deploymentInformer.Lister()}
+		synced[name] = []cache.InformerSynced{podInformer.Informer().HasSynced, deploymentInformer.Informer().HasSynced}
+		factory.Start(ctx.Done())
+	}
+
+	syncCtx, cancel := context.WithTimeout(ctx, syncTimeout)
+	defer cancel()
+	for name, cl := range c.byName {
+		if !cache.WaitForCacheSync(syncCtx.Done(), synced[name]...) {
+			slog.Warn("informers did not sync, falling back to live List calls", "context", name, "timeout", syncTimeout)
+			continue
+		}
+
+		slog.Info("informers synced", "context", name)
+		cl.listers = pending[name]
+	}
+}
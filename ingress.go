@@ -0,0 +1,87 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"k8s.io/client-go/kubernetes"
+)
+
+// getIngressCommand lists Ingresses, i.e. kubectl get ingress
+type getIngressCommand struct {
+	regexpCommand
+}
+
+func (getIngressCommand) Handle(ctx context.Context, args map[string]string, clientset kubernetes.Interface) (string, error) {
+	listOptions, err := listOptionsFromArgs(args)
+	if err != nil {
+		return "", err
+	}
+
+	// Both versions of Ingress share their shape, but not their Go types
+	var rows [][]string
+	if cl := clusterFrom(ctx); cl != nil && cl.legacy.ingress {
+		list, err := clientset.NetworkingV1beta1().Ingresses(args["namespace"]).List(ctx, listOptions)
+		if err != nil {
+			return "", fmt.Errorf("failed to list ingresses in `%s`: %w", args["namespace"], err)
+		}
+		for _, ing := range list.Items {
+			hosts := make([]string, 0, len(ing.Spec.Rules))
+			for _, rule := range ing.Spec.Rules {
+				hosts = append(hosts, rule.Host)
+			}
+			addresses := make([]string, 0, len(ing.Status.LoadBalancer.Ingress))
+			for _, lb := range ing.Status.LoadBalancer.Ingress {
+				// Each entry is either an IP or a hostname, never both
+				addresses = append(addresses, lb.IP+lb.Hostname)
+			}
+			rows = append(rows, []string{ing.Name, ingressClass(ing.Spec.IngressClassName), ingressHosts(hosts), strings.Join(addresses, ",")})
+		}
+	} else {
+		list, err := clientset.NetworkingV1().Ingresses(args["namespace"]).List(ctx, listOptions)
+		if err != nil {
+			return "", fmt.Errorf("failed to list ingresses in `%s`: %w", args["namespace"], err)
+		}
+		for _, ing := range list.Items {
+			hosts := make([]string, 0, len(ing.Spec.Rules))
+			for _, rule := range ing.Spec.Rules {
+				hosts = append(hosts, rule.Host)
+			}
+			addresses := make([]string, 0, len(ing.Status.LoadBalancer.Ingress))
+			for _, lb := range ing.Status.LoadBalancer.Ingress {
+				// Each entry is either an IP or a hostname, never both
+				addresses = append(addresses, lb.IP+lb.Hostname)
+			}
+			rows = append(rows, []string{ing.Name, ingressClass(ing.Spec.IngressClassName), ingressHosts(hosts), strings.Join(addresses, ",")})
+		}
+	}
+	if len(rows) == 0 {
+		return "No resources found in " + args["namespace"], nil
+	}
+
+	return renderSortedTable(args, []string{"NAME", "CLASS", "HOSTS", "ADDRESS"}, rows)
+}
+
+// ingressClass renders an Ingress's class name, which is optional
+func ingressClass(className *string) string {
+	if className == nil || *className == "" {
+		return "<none>"
+	}
+
+	return *className
+}
+
+// ingressHosts joins the hosts of an Ingress's rules, where an empty host matches every host like kubectl's *
+func ingressHosts(hosts []string) string {
+	if len(hosts) == 0 {
+		return "*"
+	}
+	for i, host := range hosts {
+		if host == "" {
+			hosts[i] = "*"
+		}
+	}
+
+	return strings.Join(hosts, ",")
+}
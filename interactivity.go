@@ -0,0 +1,69 @@
+package main
+
+import (
+	"context"
+	"log/slog"
+	"strings"
+
+	"github.com/slack-go/slack"
+)
+
+const (
+	// refreshActionID identifies the button that reruns the command whose text is its value
+	refreshActionID = "refresh"
+	// podActionID identifies the menu of commands to run against a pod, whose options' values are command text
+	podActionID = "pod"
+)
+
+// handleInteraction runs the command behind a button or menu on one of our replies, replying in its thread.
+// Interactions carry plain command text, so they go through the same checks as if the user had typed it.
+func (b *bot) handleInteraction(ctx context.Context, callback slack.InteractionCallback) {
+	if callback.Type != slack.InteractionTypeBlockActions {
+		return
+	}
+
+	for _, action := range callback.ActionCallback.BlockActions {
+		var text string
+		fromHome := strings.HasPrefix(action.ActionID, homeActionPrefix)
+		switch {
+		case action.ActionID == refreshActionID, fromHome:
+			text = action.Value
+		case action.ActionID == podActionID:
+			text = action.SelectedOption.Value
+		default:
+			continue
+		}
+
+		var m message
+		if fromHome {
+			// The App Home tab isn't a conversation, so reply in the user's messages with us instead
+			channel, err := b.homeChannel(callback.User.ID)
+			if err != nil {
+				slog.Error("failed to run App Home action", "user", callback.User.ID, "err", err)
+				continue
+			}
+			m = message{channel: channel, user: callback.User.ID, text: text}
+		} else {
+			threadTimestamp := callback.Message.ThreadTimestamp
+			if threadTimestamp == "" {
+				threadTimestamp = callback.Message.Timestamp
+			}
+			m = message{channel: callback.Channel.ID, user: callback.User.ID, text: text, threadTimestamp: threadTimestamp}
+		}
+		if refusal := b.refusal(m); refusal != "" {
+			b.send(m, refusal)
+			continue
+		}
+		slog.Info("interaction received", "user", m.user, "channel", m.channel, "text", text)
+
+		reply, err := b.respond(ctx, m, text)
+		if err != nil {
+			b.reportError(m, err)
+			continue
+		}
+		// Streams, blocks and snippets post their own replies
+		if reply != "" {
+			b.send(m, reply)
+		}
+	}
+}
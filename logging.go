@@ -0,0 +1,27 @@
+package main
+
+import (
+	"fmt"
+	"log/slog"
+	"os"
+)
+
+// setupLogging installs a structured logger at the level named by LOG_LEVEL (debug, info, warn or error,
+// default info) as the default logger
+func setupLogging(level string) error {
+	var logLevel slog.Level
+	if level != "" {
+		if err := logLevel.UnmarshalText([]byte(level)); err != nil {
+			return fmt.Errorf("invalid LOG_LEVEL %q, must be one of debug, info, warn or error", level)
+		}
+	}
+
+	slog.SetDefault(slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: logLevel})))
+	return nil
+}
+
+// fatal logs msg at error level and exits non-zero
+func fatal(msg string, args ...any) {
+	slog.Error(msg, args...)
+	os.Exit(1)
+}
@@ -0,0 +1,203 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"html"
+	"math"
+	"regexp"
+	"strings"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+const (
+	// logTailLines is how many lines of logs are fetched for kubectl logs
+	logTailLines int64 = 50
+	// logGrepLines is how many lines of logs are searched by logs grep, since matches are usually few and far between
+	logGrepLines int64 = 1000
+	// logSinceLimit caps the logs fetched for --since, which has no line limit, at 1MB
+	logSinceLimit int64 = 1 << 20
+)
+
+// logsCommand fetches recent container logs, i.e. kubectl logs
+type logsCommand struct {
+	regexpCommand
+}
+
+func (logsCommand) Handle(ctx context.Context, args map[string]string, clientset kubernetes.Interface) (string, error) {
+	pattern, err := logsPattern(args)
+	if err != nil {
+		return "", err
+	}
+	options, err := logOptions(args, pattern != nil)
+	if err != nil {
+		return "", err
+	}
+	container, reply, err := logsContainer(ctx, args, clientset)
+	if reply != "" || err != nil {
+		return reply, err
+	}
+
+	options.Container = container
+	raw, err := clientset.CoreV1().Pods(args["namespace"]).GetLogs(args["pod"], options).DoRaw(ctx)
+	if noPreviousLogs(args, err) {
+		return fmt.Sprintf("no previous logs for pod `%s`, its container hasn't restarted", args["pod"]), nil
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to get logs for pod `%s` in `%s`: %w", args["pod"], args["namespace"], err)
+	}
+	if pattern == nil {
+		return renderLogs(raw), nil
+	}
+
+	lines := strings.Split(strings.TrimSuffix(string(raw), "\n"), "\n")
+	if len(raw) == 0 {
+		lines = nil
+	}
+	var matched []string
+	for _, line := range lines {
+		if pattern.MatchString(line) {
+			matched = append(matched, line)
+		}
+	}
+	summary := fmt.Sprintf("showing %d of %d lines matching /%s/", len(matched), len(lines), pattern)
+	if len(matched) == 0 {
+		return summary, nil
+	}
+	return summary + "\n" + renderLogs([]byte(strings.Join(matched, "\n"))), nil
+}
+
+// logOptions works out which logs to fetch: the previous container's with -p, and everything since --since if
+// it's given, otherwise the last logTailLines, or logGrepLines when searching them
+func logOptions(args map[string]string, searching bool) (*corev1.PodLogOptions, error) {
+	if args["since"] != "" {
+		since, err := time.ParseDuration(args["since"])
+		if err != nil || since <= 0 {
+			return nil, fmt.Errorf("invalid `--since=%s`, must be a positive duration like 10m or 1h30m", args["since"])
+		}
+		// Like kubectl, round up to the whole seconds the API takes
+		seconds, limit := int64(math.Ceil(since.Seconds())), logSinceLimit
+		return &corev1.PodLogOptions{SinceSeconds: &seconds, LimitBytes: &limit, Previous: args["previous"] != ""}, nil
+	}
+
+	tailLines := logTailLines
+	if searching {
+		tailLines = logGrepLines
+	}
+	return &corev1.PodLogOptions{TailLines: &tailLines, Previous: args["previous"] != ""}, nil
+}
+
+// noPreviousLogs reports whether err is the API server saying there's no previous container to get logs from for
+// logs -p. It's a bad request like any other, e.g. naming a container the pod doesn't have, so it's told apart by
+// the kubelet's wording: previous terminated container "app" in pod "web" not found.
+func noPreviousLogs(args map[string]string, err error) bool {
+	return args["previous"] != "" && apierrors.IsBadRequest(err) && strings.Contains(err.Error(), "previous terminated container")
+}
+
+// logsPattern compiles the grep pattern in args, or returns nil if there isn't one
+func logsPattern(args map[string]string) (*regexp.Regexp, error) {
+	if args["grep"] == "" {
+		return nil, nil
+	}
+	// Slack escapes <, > and & in messages
+	pattern, err := regexp.Compile(html.UnescapeString(args["grep"]))
+	if err != nil {
+		return nil, fmt.Errorf("invalid grep pattern `%s`: %w", args["grep"], err)
+	}
+
+	return pattern, nil
+}
+
+func (logsCommand) Streams(args map[string]string) bool {
+	return args["follow"] != ""
+}
+
+// Stream follows the logs, i.e. kubectl logs -f, updating with the most recent lines that fit in one message
+func (logsCommand) Stream(ctx context.Context, args map[string]string, clientset kubernetes.Interface, update func(string)) error {
+	pattern, err := logsPattern(args)
+	if err != nil {
+		return err
+	}
+	options, err := logOptions(args, false)
+	if err != nil {
+		return err
+	}
+	container, reply, err := logsContainer(ctx, args, clientset)
+	if reply != "" || err != nil {
+		update(reply)
+		return err
+	}
+
+	// Only the latest lines are kept while following, so there's nothing to cap
+	options.Container, options.Follow, options.LimitBytes = container, true, nil
+	logs, err := clientset.CoreV1().Pods(args["namespace"]).GetLogs(args["pod"], options).Stream(ctx)
+	if noPreviousLogs(args, err) {
+		update(fmt.Sprintf("no previous logs for pod `%s`, its container hasn't restarted", args["pod"]))
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to follow logs for pod `%s` in `%s`: %w", args["pod"], args["namespace"], err)
+	}
+	defer logs.Close()
+
+	var lines []string
+	size := 0
+	scanner := bufio.NewScanner(logs)
+	for scanner.Scan() {
+		if pattern != nil && !pattern.MatchString(scanner.Text()) {
+			continue
+		}
+		lines = append(lines, scanner.Text())
+		size += len(scanner.Text()) + 1
+		// Drop the oldest lines once they no longer fit in one message along with the code fence
+		for len(lines) > 1 && size > messageLimit-len("```\n```") {
+			size -= len(lines[0]) + 1
+			lines = lines[1:]
+		}
+		update(renderLogs([]byte(strings.Join(lines, "\n"))))
+	}
+	// The stream is cut off when ctx ends, which is how following normally stops
+	if err := scanner.Err(); err != nil && ctx.Err() == nil {
+		return fmt.Errorf("failed to follow logs for pod `%s` in `%s`: %w", args["pod"], args["namespace"], err)
+	}
+
+	return nil
+}
+
+// logsContainer works out which of the pod's containers to get logs from. If that needs the user to pick one
+// it returns a reply asking them to instead.
+func logsContainer(ctx context.Context, args map[string]string, clientset kubernetes.Interface) (string, string, error) {
+	pod, err := clientset.CoreV1().Pods(args["namespace"]).Get(ctx, args["pod"], metav1.GetOptions{})
+	if err != nil {
+		return "", "", fmt.Errorf("failed to get pod `%s` in `%s`: %w", args["pod"], args["namespace"], err)
+	}
+	container := args["container"]
+	if container == "" && len(pod.Spec.Containers) > 1 {
+		names := make([]string, 0, len(pod.Spec.Containers))
+		for _, c := range pod.Spec.Containers {
+			names = append(names, c.Name)
+		}
+		return "", fmt.Sprintf("pod `%s` has multiple containers, retry with `-c` and one of: %s", pod.Name, strings.Join(names, ", ")), nil
+	}
+
+	return container, "", nil
+}
+
+// renderLogs wraps raw logs in a code block
+func renderLogs(raw []byte) string {
+	var logs strings.Builder
+	logs.WriteString("```\n")
+	logs.Write(raw)
+	if len(raw) > 0 && raw[len(raw)-1] != '\n' {
+		logs.WriteString("\n")
+	}
+	logs.WriteString("```")
+
+	return logs.String()
+}
@@ -0,0 +1,28 @@
+package main
+
+import (
+	"errors"
+	"testing"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+)
+
+func TestNoPreviousLogs(t *testing.T) {
+	previous := map[string]string{"previous": "-p"}
+	for _, tc := range []struct {
+		name string
+		args map[string]string
+		err  error
+		want bool
+	}{
+		{"not restarted", previous, apierrors.NewBadRequest(`previous terminated container "app" in pod "web" not found`), true},
+		{"unknown container", previous, apierrors.NewBadRequest(`container sidecar is not valid for pod web`), false},
+		{"without -p", map[string]string{}, apierrors.NewBadRequest(`previous terminated container "app" in pod "web" not found`), false},
+		{"other error", previous, errors.New("connection refused"), false},
+		{"no error", previous, nil, false},
+	} {
+		if got := noPreviousLogs(tc.args, tc.err); got != tc.want {
+			t.Errorf("%s: noPreviousLogs = %t, want %t", tc.name, got, tc.want)
+		}
+	}
+}
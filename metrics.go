@@ -0,0 +1,65 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+var (
+	commandsTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "mibot_commands_total",
+		Help: "Commands handled, by command, channel and whether they succeeded.",
+	}, []string{"command", "channel", "result"})
+
+	slackReconnectsTotal = promauto.NewCounter(prometheus.CounterOpts{
+		Name: "mibot_slack_reconnects_total",
+		Help: "Times the RTM connection to Slack was re-established after dropping.",
+	})
+
+	kubernetesAPIDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "mibot_kubernetes_api_request_duration_seconds",
+		Help:    "Duration of Kubernetes API requests, excluding long-running watches.",
+		Buckets: prometheus.DefBuckets,
+	}, []string{"method"})
+)
+
+// commandName is the short name of cmd used in metrics, e.g. getPod for getPodCommand
+func commandName(cmd Command) string {
+	name := strings.TrimPrefix(fmt.Sprintf("%T", cmd), "main.")
+	return strings.TrimSuffix(name, "Command")
+}
+
+// recordCommand counts a handled command as a success or failure
+func recordCommand(cmd Command, channel string, err error) {
+	result := "success"
+	if err != nil {
+		result = "failure"
+	}
+	commandsTotal.WithLabelValues(commandName(cmd), channel, result).Inc()
+}
+
+// instrumentedTransport times every Kubernetes API request made through it
+type instrumentedTransport struct {
+	next http.RoundTripper
+}
+
+func (t instrumentedTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	// Watches stay open for minutes and would drown out everything else
+	if req.URL.Query().Get("watch") == "true" {
+		return t.next.RoundTrip(req)
+	}
+
+	start := time.Now()
+	resp, err := t.next.RoundTrip(req)
+	kubernetesAPIDuration.WithLabelValues(req.Method).Observe(time.Since(start).Seconds())
+	return resp, err
+}
+
+func instrumentTransport(next http.RoundTripper) http.RoundTripper {
+	return instrumentedTransport{next: next}
+}
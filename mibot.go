@@ -3,153 +3,229 @@ package main
 import (
 	"context"
 	"flag"
-	"fmt"
-	"log"
+	"log/slog"
 	"os"
-	"regexp"
+	"os/signal"
 	"strconv"
 	"strings"
+	"sync"
+	"syscall"
+	"time"
 
-	"github.com/nlopes/slack"
-
-	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
-	"k8s.io/client-go/kubernetes"
-	"k8s.io/client-go/tools/clientcmd"
+	"github.com/slack-go/slack"
+	"golang.org/x/time/rate"
 )
 
 func main() {
-	slackToken := os.Getenv("SLACK_TOKEN")
+	// Settings can be kept in a file, so apply it before anything reads the env vars it sets
+	if path := os.Getenv("CONFIG_FILE"); path != "" {
+		if err := loadSettings(path); err != nil {
+			fatal(err.Error())
+		}
+	}
+
+	// One token per workspace, with the app tokens in the same order for Socket Mode
+	slackTokens := splitList(os.Getenv("SLACK_TOKEN"))
+	slackAppTokens := splitList(os.Getenv("SLACK_APP_TOKEN"))
 	kubeconfigPath := os.Getenv("KUBECONFIG")
 
 	kubeconfig := flag.String("kubeconfig", kubeconfigPath, "absolute path to the kubeconfig file")
+	transport := flag.String("transport", "rtm", "how to connect to Slack, either rtm or socket")
 	flag.Parse()
 
-	// use the current context in kubeconfig
-	config, err := clientcmd.BuildConfigFromFlags("", *kubeconfig)
+	if err := setupLogging(os.Getenv("LOG_LEVEL")); err != nil {
+		fatal(err.Error())
+	}
+
+	// Fail fast on missing tokens rather than connecting and hitting an auth error later
+	if len(slackTokens) == 0 {
+		fatal("SLACK_TOKEN is required, set it to the bot user OAuth token (xoxb-...) from your Slack app's settings, or a comma-separated list of them for several workspaces")
+	}
+	if *transport == "socket" {
+		if len(slackAppTokens) != len(slackTokens) {
+			fatal("SLACK_APP_TOKEN is required for --transport socket, set it to an app-level token (xapp-...) with the connections:write scope for each SLACK_TOKEN")
+		}
+		for _, token := range slackAppTokens {
+			if !strings.HasPrefix(token, "xapp-") {
+				fatal("SLACK_APP_TOKEN must be app-level tokens (xapp-...) with the connections:write scope")
+			}
+		}
+	} else {
+		// RTM doesn't use app tokens, so don't let connect set them
+		slackAppTokens = make([]string, len(slackTokens))
+	}
+
+	if path := os.Getenv("COMMANDS_CONFIG"); path != "" {
+		definitions, err := loadCommandDefinitions(path)
+		if err != nil {
+			fatal(err.Error())
+		}
+		setCommandDefinitions(definitions)
+	}
+	if prefixes := splitList(os.Getenv("COMMAND_PREFIX")); len(prefixes) > 0 {
+		setCommandPrefixes(prefixes)
+	}
+
+	// build a clientset for every context in kubeconfig
+	kubeClusters, err := loadClusters(*kubeconfig)
 	if err != nil {
-		panic(err.Error())
+		fatal(err.Error())
 	}
+	kubeClusters.discoverAPIs()
 
-	// create the clientset
-	clientset, err := kubernetes.NewForConfig(config)
+	impersonation, err := loadImpersonation(os.Getenv("IMPERSONATION_CONFIG"))
 	if err != nil {
-		panic(err.Error())
+		fatal(err.Error())
+	}
+
+	cacheTTL := 10 * time.Second
+	if ttl := os.Getenv("CACHE_TTL"); ttl != "" {
+		if cacheTTL, err = time.ParseDuration(ttl); err != nil {
+			fatal("invalid CACHE_TTL, must be a duration like 10s", "err", err)
+		}
+	}
+
+	apiTimeout := 10 * time.Second
+	if timeout := os.Getenv("API_TIMEOUT"); timeout != "" {
+		if apiTimeout, err = time.ParseDuration(timeout); err != nil {
+			fatal("invalid API_TIMEOUT, must be a duration like 10s", "err", err)
+		}
 	}
 
-	// Regular expressions for the bot to match against
-	getDeployRegexp := regexp.MustCompile(`k(ubectl)? get deploy(ment)?(s)? -n (?P<namespace>.*)`)
-	getPodRegexp := regexp.MustCompile(`k(ubectl)? get po(d)?(s)? -n (?P<namespace>.*)`)
+	rateLimit, rateBurst := 1.0, 5
+	if limit := os.Getenv("RATE_LIMIT"); limit != "" {
+		if rateLimit, err = strconv.ParseFloat(limit, 64); err != nil {
+			fatal("invalid RATE_LIMIT, must be a number of commands per second", "err", err)
+		}
+	}
+	if burst := os.Getenv("RATE_BURST"); burst != "" {
+		if rateBurst, err = strconv.Atoi(burst); err != nil {
+			fatal("invalid RATE_BURST, must be a whole number of commands", "err", err)
+		}
+	}
 
-	// Initialize Slack bot
-	api := slack.New(
-		slackToken,
-		slack.OptionDebug(true),
-		slack.OptionLog(log.New(os.Stdout, "slack-bot: ", log.Lshortfile|log.LstdFlags)),
-	)
+	streamDuration, streamInterval := defaultStreamDuration, defaultStreamInterval
+	if duration := os.Getenv("WATCH_DURATION"); duration != "" {
+		if streamDuration, err = time.ParseDuration(duration); err != nil {
+			fatal("invalid WATCH_DURATION, must be a duration like 2m", "err", err)
+		}
+	}
+	if interval := os.Getenv("WATCH_INTERVAL"); interval != "" {
+		if streamInterval, err = time.ParseDuration(interval); err != nil || streamInterval <= 0 {
+			fatal("invalid WATCH_INTERVAL, must be a positive duration like 3s", "interval", interval)
+		}
+	}
 
-	// Start RTM connection
-	rtm := api.NewRTM()
-	go rtm.ManageConnection()
+	workers := 4
+	if n := os.Getenv("WORKERS"); n != "" {
+		if workers, err = strconv.Atoi(n); err != nil || workers < 1 {
+			fatal("invalid WORKERS, must be a whole number of at least 1", "workers", n)
+		}
+	}
 
-	for msg := range rtm.IncomingEvents {
-		//fmt.Print("Event Received: %s\n, msg.Data")
-		switch ev := msg.Data.(type) {
-		case *slack.HelloEvent:
-			// Ignore hello
+	execAllowedCommands = splitList(os.Getenv("EXEC_ALLOWED_CMDS"))
+	statusEmoji = os.Getenv("STATUS_EMOJI") != "false"
 
-		case *slack.ConnectedEvent:
-			// Ignore when the bot first connects
+	unknownReply := os.Getenv("UNKNOWN_REPLY")
+	if unknownReply == "" {
+		unknownReply = defaultUnknownReply
+	}
 
-		case *slack.MessageEvent:
-			botTagString := fmt.Sprintf("<@%s>", rtm.GetInfo().User.ID)
-			if !strings.Contains(ev.Msg.Text, botTagString) {
-				continue
-			}
+	audit, err := newAuditor(os.Getenv("AUDIT_LOG"), os.Getenv("AUDIT_WEBHOOK"))
+	if err != nil {
+		fatal(err.Error())
+	}
 
-			if getDeployRegexp.MatchString(ev.Msg.Text) {
-				args := regexpSubexpMatch(getDeployRegexp, ev.Msg.Text)
-				deploymentsClient := clientset.AppsV1().Deployments(args["namespace"])
-
-				var deployments strings.Builder
-				list, err := deploymentsClient.List(context.TODO(), metav1.ListOptions{})
-				if err != nil {
-					panic(err)
-				}
-				deployments.WriteString("```\n")
-				for _, d := range list.Items {
-					deployments.WriteString(d.Name + "\n")
-				}
-				deployments.WriteString("```")
-				fmt.Printf(deployments.String())
-				rtm.SendMessage(rtm.NewOutgoingMessage(deployments.String(), ev.Channel))
-			} else if getPodRegexp.MatchString(ev.Msg.Text) {
-				args := regexpSubexpMatch(getPodRegexp, ev.Msg.Text)
-				podsClient := clientset.CoreV1().Pods(args["namespace"])
-
-				var pods strings.Builder
-				list, err := podsClient.List(context.TODO(), metav1.ListOptions{})
-				if err != nil {
-					panic(err)
-				}
-				pods.WriteString("```\n")
-				for _, po := range list.Items {
-					runningContainers := 0
-					for _, container := range po.Status.ContainerStatuses {
-						if container.State.Running != nil {
-							runningContainers++
-						}
-					}
-					pods.WriteString(po.Name + "\t" + string(po.Status.Phase) + "\t" + strconv.Itoa(runningContainers) + "/" + strconv.Itoa(len(po.Status.ContainerStatuses)) + "\n")
-				}
-				pods.WriteString("```")
-				fmt.Printf(pods.String())
-				rtm.SendMessage(rtm.NewOutgoingMessage(pods.String(), ev.Channel))
-			} else if strings.Contains(ev.Msg.Text, "help") {
-				rtm.SendMessage(rtm.NewOutgoingMessage("```\nkubectl get deploy -n $namespace\nkubectl get po -n $namespace\n```", ev.Channel))
-			} else {
-				rtm.SendMessage(rtm.NewOutgoingMessage("I'm mibot. I'm alive, but idk what you want from me! Try help? :narwhal-dancing:", ev.Channel))
-			}
+	store, err := newStore(os.Getenv("REDIS_URL"))
+	if err != nil {
+		fatal(err.Error())
+	}
 
-			// pods, err := clientset.CoreV1().Pods("").List(metav1.ListOptions{})
-			// if err != nil {
-			// 	panic(err.Error())
-			// }
-			// rtm.SendMessage(rtm.NewOutgoingMessage("There are %s pods in the cluster", strconv.Itoa(len(pods.Items))))
-			// fmt.Printf("There are %d pods in the cluster\n", len(pods.Items))
-			// rtm.SendMessage(rtm.NewOutgoingMessage("I'm mibot. I'm alive!", ev.Channel))
+	// Everything but the Slack client is shared by the bots for each workspace
+	base := &bot{
+		clusters: kubeClusters,
+		auth:     newAuthorizerFromEnv(),
+		cache:    newReplyCache(cacheTTL),
+		auditor:  audit,
 
-		case *slack.PresenceChangeEvent:
-			fmt.Printf("Presence Change: %v\n", ev)
+		apiTimeout: apiTimeout,
 
-		case *slack.LatencyReport:
-			fmt.Printf("Current latency: %v\n", ev.Value)
+		rateLimiter:   newUserRateLimiter(rate.Limit(rateLimit), rateBurst, store),
+		impersonation: impersonation,
+		confirmations: newConfirmations(store),
+		streams:       newStreams(),
+		seen:          newRecentMessages(dedupeWindow, dedupeSize),
 
-		case *slack.DesktopNotificationEvent:
-			fmt.Printf("Desktop Notification: %v\n", ev)
+		threadReplies: os.Getenv("THREAD_REPLIES") != "false",
+		paginate:      os.Getenv("LARGE_REPLIES") == "paginate",
+		blocks:        os.Getenv("OUTPUT_FORMAT") == "blocks",
+		readOnly:      os.Getenv("READ_ONLY") == "true",
 
-		case *slack.RTMError:
-			fmt.Printf("Error: %s\n", ev.Error())
+		defaultNamespace: os.Getenv("DEFAULT_NAMESPACE"),
 
-		case *slack.InvalidAuthEvent:
-			fmt.Printf("Invalid credentials")
-			return
+		unknownReply:   unknownReply,
+		quietOnUnknown: os.Getenv("QUIET_ON_UNKNOWN") == "true",
 
-		default:
+		streamDuration: streamDuration,
+		streamInterval: streamInterval,
+	}
 
-			// Ignore other events..
-			// fmt.Printf("Unexpected: %v\n", msg.Data)
+	// Initialize a Slack bot per workspace
+	options := []slack.Option{
+		// The Slack protocol is very chatty, so only log it when explicitly asked to
+		slack.OptionDebug(os.Getenv("SLACK_DEBUG") == "true"),
+		slack.OptionLog(slog.NewLogLogger(slog.Default().Handler(), slog.LevelDebug)),
+	}
+	bots := make(workspaces, len(slackTokens))
+	for i, token := range slackTokens {
+		b, teamID, err := connect(base, token, slackAppTokens[i], options)
+		if err != nil {
+			fatal("failed to authenticate with Slack", "token", i+1, "err", err)
 		}
+		bots[teamID] = b
 	}
-}
 
-func regexpSubexpMatch(r *regexp.Regexp, str string) map[string]string {
-	match := r.FindStringSubmatch(str)
-	subexpMatchMap := make(map[string]string)
-	for i, name := range r.SubexpNames() {
-		if i != 0 {
-			subexpMatchMap[name] = match[i]
+	// Stop serving and disconnect cleanly when Kubernetes or a user asks us to
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	healthPort := os.Getenv("HEALTH_PORT")
+	if healthPort == "" {
+		healthPort = "8080"
+	}
+	startHealthServer(ctx, ":"+healthPort, bots.ready)
+
+	// Slash commands need Slack to reach us over HTTP, so they're only served when we can verify its requests
+	if signingSecret := os.Getenv("SLACK_SIGNING_SECRET"); signingSecret != "" {
+		httpPort := os.Getenv("HTTP_PORT")
+		if httpPort == "" {
+			httpPort = "3000"
 		}
+		startSlackHTTPServer(ctx, ":"+httpPort, bots, signingSecret)
 	}
 
-	return subexpMatchMap
+	// Serve pod and deployment queries from informer caches rather than listing on every command
+	kubeClusters.startInformers(ctx, informerSyncTimeout)
+
+	var run func(context.Context, *bot)
+	switch *transport {
+	case "rtm":
+		run = runRTM
+	case "socket":
+		run = runSocketMode
+	default:
+		fatal("unknown transport, must be rtm or socket", "transport", *transport)
+	}
+
+	var wg sync.WaitGroup
+	for _, b := range bots {
+		b := b
+		b.startWorkers(ctx, workers)
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			run(ctx, b)
+		}()
+	}
+	wg.Wait()
 }
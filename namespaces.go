@@ -0,0 +1,38 @@
+package main
+
+import (
+	"context"
+	"fmt"
+
+	"k8s.io/client-go/kubernetes"
+)
+
+// getNamespacesCommand lists the Namespaces mibot may be used in, i.e. kubectl get ns
+type getNamespacesCommand struct {
+	regexpCommand
+}
+
+func (getNamespacesCommand) Handle(ctx context.Context, args map[string]string, clientset kubernetes.Interface) (string, error) {
+	listOptions, err := listOptionsFromArgs(args)
+	if err != nil {
+		return "", err
+	}
+	list, err := clientset.CoreV1().Namespaces().List(ctx, listOptions)
+	if err != nil {
+		return "", fmt.Errorf("failed to list namespaces: %w", err)
+	}
+
+	rows := make([][]string, 0, len(list.Items))
+	for _, ns := range list.Items {
+		// Only show the namespaces commands may actually be run against
+		if !namespaceVisible(ctx, ns.Name) {
+			continue
+		}
+		rows = append(rows, []string{ns.Name, string(ns.Status.Phase), age(ns.CreationTimestamp)})
+	}
+	if len(rows) == 0 {
+		return "No resources found", nil
+	}
+
+	return renderSortedTable(args, []string{"NAME", "STATUS", "AGE"}, rows)
+}
@@ -0,0 +1,97 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/kubernetes"
+	"sigs.k8s.io/yaml"
+)
+
+// dumpableResource is a resource whose objects can be dumped with -o json|yaml
+type dumpableResource struct {
+	resource   schema.GroupVersionResource
+	namespaced bool
+}
+
+// dumpableResources maps the names kubectl accepts for each resource that can be dumped to the resource. Secrets
+// are deliberately missing so the bot can't be used to leak their contents into a channel.
+var dumpableResources = func() map[string]dumpableResource {
+	resources := make(map[string]dumpableResource)
+	for _, r := range []struct {
+		names      []string
+		resource   schema.GroupVersionResource
+		namespaced bool
+	}{
+		{[]string{"po", "pod", "pods"}, schema.GroupVersionResource{Version: "v1", Resource: "pods"}, true},
+		{[]string{"svc", "service", "services"}, schema.GroupVersionResource{Version: "v1", Resource: "services"}, true},
+		{[]string{"cm", "configmap", "configmaps"}, schema.GroupVersionResource{Version: "v1", Resource: "configmaps"}, true},
+		{[]string{"pvc", "persistentvolumeclaim", "persistentvolumeclaims"}, schema.GroupVersionResource{Version: "v1", Resource: "persistentvolumeclaims"}, true},
+		{[]string{"pv", "persistentvolume", "persistentvolumes"}, schema.GroupVersionResource{Version: "v1", Resource: "persistentvolumes"}, false},
+		{[]string{"no", "node", "nodes"}, schema.GroupVersionResource{Version: "v1", Resource: "nodes"}, false},
+		{[]string{"ns", "namespace", "namespaces"}, schema.GroupVersionResource{Version: "v1", Resource: "namespaces"}, false},
+		{[]string{"deploy", "deployment", "deployments"}, schema.GroupVersionResource{Group: "apps", Version: "v1", Resource: "deployments"}, true},
+		{[]string{"sts", "statefulset", "statefulsets"}, schema.GroupVersionResource{Group: "apps", Version: "v1", Resource: "statefulsets"}, true},
+		{[]string{"ds", "daemonset", "daemonsets"}, schema.GroupVersionResource{Group: "apps", Version: "v1", Resource: "daemonsets"}, true},
+		{[]string{"rs", "replicaset", "replicasets"}, schema.GroupVersionResource{Group: "apps", Version: "v1", Resource: "replicasets"}, true},
+		{[]string{"job", "jobs"}, schema.GroupVersionResource{Group: "batch", Version: "v1", Resource: "jobs"}, true},
+		{[]string{"ing", "ingress", "ingresses"}, schema.GroupVersionResource{Group: "networking.k8s.io", Version: "v1", Resource: "ingresses"}, true},
+	} {
+		for _, name := range r.names {
+			resources[name] = dumpableResource{resource: r.resource, namespaced: r.namespaced}
+		}
+	}
+	return resources
+}()
+
+// getOutputCommand dumps a single object as JSON or YAML, i.e. kubectl get pod foo -o yaml
+type getOutputCommand struct {
+	regexpCommand
+}
+
+func (getOutputCommand) Handle(ctx context.Context, args map[string]string, _ kubernetes.Interface) (string, error) {
+	r, ok := dumpableResources[args["resource"]]
+	if !ok {
+		return fmt.Sprintf("can't show `%s` with -o, try a pod, deployment, service or configmap", args["resource"]), nil
+	}
+	namespace := ""
+	if r.namespaced {
+		if namespace = args["namespace"]; namespace == "" {
+			return fmt.Sprintf("%s are namespaced, retry with `-n $namespace`", r.resource.Resource), nil
+		}
+	}
+	cl := clusterFrom(ctx)
+	if cl == nil || cl.dynamic == nil {
+		return "", errors.New("no dynamic client for this cluster")
+	}
+
+	obj, err := cl.dynamic.Resource(r.resource).Namespace(namespace).Get(ctx, args["name"], metav1.GetOptions{})
+	if err != nil {
+		return "", fmt.Errorf("failed to get %s `%s`: %w", r.resource.Resource, args["name"], err)
+	}
+	// managedFields is server-side apply bookkeeping that dwarfs the rest of the object
+	if args["showManagedFields"] == "" {
+		unstructured.RemoveNestedField(obj.Object, "metadata", "managedFields")
+	}
+
+	var out []byte
+	if args["output"] == "json" {
+		out, err = json.MarshalIndent(obj.Object, "", "    ")
+	} else {
+		out, err = yaml.Marshal(obj.Object)
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to render %s `%s` as %s: %w", r.resource.Resource, args["name"], args["output"], err)
+	}
+
+	return string(out), nil
+}
+
+func (getOutputCommand) Snippet(args map[string]string) (string, string) {
+	return args["name"] + "." + args["output"], args["output"]
+}
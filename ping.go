@@ -0,0 +1,40 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"sync/atomic"
+	"time"
+
+	"k8s.io/client-go/kubernetes"
+)
+
+type latencyKey struct{}
+
+// withLatency returns a copy of ctx carrying the latest Slack latency, as recorded by the transport
+func withLatency(ctx context.Context, latency *atomic.Int64) context.Context {
+	return context.WithValue(ctx, latencyKey{}, latency)
+}
+
+// pingCommand reports how quickly Slack and the Kubernetes API are answering, to tell a slow bot from a slow
+// cluster
+type pingCommand struct {
+	regexpCommand
+}
+
+func (pingCommand) Handle(ctx context.Context, args map[string]string, clientset kubernetes.Interface) (string, error) {
+	start := time.Now()
+	if _, err := clientset.Discovery().ServerVersion(); err != nil {
+		return "", fmt.Errorf("pong, but the Kubernetes API didn't answer: %w", err)
+	}
+	api := time.Since(start).Round(time.Millisecond)
+
+	slack := "unknown"
+	if latency, ok := ctx.Value(latencyKey{}).(*atomic.Int64); ok && latency.Load() > 0 {
+		slack = time.Duration(latency.Load()).Round(time.Millisecond).String()
+	}
+	return fmt.Sprintf("pong, Slack latency %s, Kubernetes API round trip %s", slack, api), nil
+}
+
+// Uncached makes every ping measure afresh
+func (pingCommand) Uncached() {}
@@ -0,0 +1,72 @@
+package main
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+
+	"github.com/slack-go/slack"
+)
+
+// progressPlaceholder stands in for results that haven't arrived yet
+const progressPlaceholder = "_fetching…_"
+
+// progressCommand is implemented by commands that run several queries and report each as it arrives with
+// reportProgress, e.g. get all
+type progressCommand interface {
+	// ReportsProgress marks the command as one worth posting a reply for before it finishes
+	ReportsProgress()
+}
+
+type progressKey struct{}
+
+// withProgress returns a copy of ctx carrying update for commands to show a partial reply with
+func withProgress(ctx context.Context, update func(partial string)) context.Context {
+	return context.WithValue(ctx, progressKey{}, update)
+}
+
+// reportProgress shows partial as the reply so far, if whoever runs the command in ctx is showing progress
+func reportProgress(ctx context.Context, partial string) {
+	if update, ok := ctx.Value(progressKey{}).(func(string)); ok {
+		update(partial)
+	}
+}
+
+// runWithProgress posts a placeholder reply to m, edits it as cmd reports progress, and finally replaces it with
+// the whole reply. Like the other replies that post themselves, it returns "" once it has.
+func (b *bot) runWithProgress(ctx context.Context, m message, cmd Command, kubeContext string, args map[string]string) (string, error) {
+	ts, err := b.post(m, progressPlaceholder)
+	if err != nil {
+		return b.run(ctx, m.user, cmd, kubeContext, args)
+	}
+	edit := func(text string) {
+		if _, _, _, err := b.api.UpdateMessage(m.channel, ts, slack.MsgOptionText(text, false)); err != nil {
+			slog.Error("failed to update reply", "channel", m.channel, "err", err)
+		}
+	}
+
+	var mu sync.Mutex
+	ctx = withProgress(ctx, func(partial string) {
+		mu.Lock()
+		defer mu.Unlock()
+		edit(partial)
+	})
+	reply, err := b.run(ctx, m.user, cmd, kubeContext, args)
+
+	mu.Lock()
+	defer mu.Unlock()
+	if err != nil {
+		// The error is reported like any other, so don't leave a half finished reply behind
+		if _, _, err := b.api.DeleteMessage(m.channel, ts); err != nil {
+			slog.Error("failed to delete reply", "channel", m.channel, "err", err)
+		}
+		return "", err
+	}
+	if len(reply) > snippetThreshold {
+		edit("done, the reply is too long to show here so it's below")
+		b.send(m, reply)
+		return "", nil
+	}
+	edit(reply)
+	return "", nil
+}
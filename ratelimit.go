@@ -0,0 +1,33 @@
+package main
+
+import (
+	"context"
+	"log/slog"
+
+	"golang.org/x/time/rate"
+)
+
+// userRateLimiter gives every Slack user their own token bucket so one user can't flood the API server
+type userRateLimiter struct {
+	limit rate.Limit
+	burst int
+	store Store
+}
+
+func newUserRateLimiter(limit rate.Limit, burst int, store Store) *userRateLimiter {
+	return &userRateLimiter{limit: limit, burst: burst, store: store}
+}
+
+// allow reports whether user may run a command now, using up one of their tokens if so. Users aren't held up by
+// a store that's unavailable.
+func (l *userRateLimiter) allow(user string) bool {
+	ctx, cancel := context.WithTimeout(context.Background(), storeTimeout)
+	defer cancel()
+	allowed, err := l.store.Allow(ctx, "ratelimit/"+user, l.limit, l.burst)
+	if err != nil {
+		slog.Warn("failed to check rate limit, allowing the command", "user", user, "err", err)
+		return true
+	}
+
+	return allowed
+}
@@ -0,0 +1,72 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"math"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	"golang.org/x/time/rate"
+)
+
+// redisStore is a Store shared by every mibot replica using the same Redis, which keeps its state across restarts
+type redisStore struct {
+	client *redis.Client
+}
+
+func newRedisStore(url string) (*redisStore, error) {
+	options, err := redis.ParseURL(url)
+	if err != nil {
+		return nil, err
+	}
+
+	return &redisStore{client: redis.NewClient(options)}, nil
+}
+
+func (s *redisStore) Put(ctx context.Context, key string, value []byte, ttl time.Duration) error {
+	return s.client.Set(ctx, "mibot:"+key, value, ttl).Err()
+}
+
+func (s *redisStore) Take(ctx context.Context, key string) ([]byte, bool, error) {
+	value, err := s.client.GetDel(ctx, "mibot:"+key).Bytes()
+	if errors.Is(err, redis.Nil) {
+		return nil, false, nil
+	}
+	if err != nil {
+		return nil, false, err
+	}
+
+	return value, true, nil
+}
+
+// allowScript is a token bucket kept as the time its bucket will next be full, in milliseconds, so a single
+// number per key is enough and Redis can expire it once the bucket has refilled
+var allowScript = redis.NewScript(`
+local now = tonumber(ARGV[1])
+local interval = tonumber(ARGV[2])
+local burst = tonumber(ARGV[3])
+local full = math.max(tonumber(redis.call("GET", KEYS[1]) or 0), now)
+if full + interval - now > burst * interval then
+	return 0
+end
+redis.call("SET", KEYS[1], full + interval, "PX", full + interval - now)
+return 1
+`)
+
+func (s *redisStore) Allow(ctx context.Context, key string, limit rate.Limit, burst int) (bool, error) {
+	if limit == rate.Inf {
+		return true, nil
+	}
+	// A bucket that never refills is one that takes longer than anyone will wait
+	interval := int64(math.MaxInt32)
+	if limit > 0 {
+		interval = max(int64(float64(time.Second/time.Millisecond)/float64(limit)), 1)
+	}
+
+	allowed, err := allowScript.Run(ctx, s.client, []string{"mibot:" + key}, time.Now().UnixMilli(), interval, burst).Int()
+	if err != nil {
+		return false, err
+	}
+	return allowed == 1, nil
+}
@@ -0,0 +1,74 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"k8s.io/client-go/kubernetes"
+)
+
+// adminCommand is implemented by commands only ADMIN_USERS may run, since they change what the whole bot does
+// rather than any one cluster
+type adminCommand interface {
+	AdminOnly()
+}
+
+// botCommand is implemented by commands that manage the bot itself rather than any one cluster, so its circuit
+// breaker and retries don't apply to them, e.g. reload-config may be what fixes an unreachable cluster
+type botCommand interface {
+	ManagesBot()
+}
+
+type clustersKey struct{}
+
+// withClusters returns a copy of ctx carrying every cluster for commands that manage them
+func withClusters(ctx context.Context, c *clusters) context.Context {
+	return context.WithValue(ctx, clustersKey{}, c)
+}
+
+// reloadConfigCommand swaps every cluster for those of another kubeconfig, so access can be rotated without
+// redeploying
+type reloadConfigCommand struct {
+	regexpCommand
+}
+
+func (reloadConfigCommand) Handle(ctx context.Context, args map[string]string, _ kubernetes.Interface) (string, error) {
+	c, ok := ctx.Value(clustersKey{}).(*clusters)
+	if !ok {
+		return "", errors.New("there are no clusters to reload")
+	}
+
+	next, err := loadClusters(args["path"])
+	if err != nil {
+		return "", err
+	}
+	next.discoverAPIs()
+	// The informers outlive this command, so only its values are kept, but their sync has to fit within its
+	// API_TIMEOUT. Clusters that don't sync in time list from the API server instead.
+	syncTimeout := informerSyncTimeout
+	if deadline, ok := ctx.Deadline(); ok && time.Until(deadline) < syncTimeout {
+		syncTimeout = time.Until(deadline)
+	}
+	next.startInformers(context.WithoutCancel(ctx), syncTimeout)
+	// Stops the informers of the clusters being replaced, while run clears the replies cached from them
+	c.swap(next)
+	slog.Info("reloaded kubeconfig", "path", args["path"], "context", c.resolve(""))
+
+	current := "the in-cluster config"
+	if name := c.resolve(""); name != "" {
+		current = fmt.Sprintf("`%s`", name)
+	}
+	return fmt.Sprintf("reloaded `%s` with %d contexts, the current context is %s", args["path"], len(c.names()), current), nil
+}
+
+// AdminOnly restricts reloading to ADMIN_USERS, since it changes what every user can reach
+func (reloadConfigCommand) AdminOnly() {}
+
+// ManagesBot runs reloads whatever state the current clusters are in
+func (reloadConfigCommand) ManagesBot() {}
+
+// Uncached makes every reload happen
+func (reloadConfigCommand) Uncached() {}
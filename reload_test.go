@@ -0,0 +1,64 @@
+package main
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func TestReloadConfigReplacesClusters(t *testing.T) {
+	kubeconfig := filepath.Join(t.TempDir(), "kubeconfig")
+	// Nothing listens on port 1, so discovery fails fast and the informers never sync
+	err := os.WriteFile(kubeconfig, []byte(`apiVersion: v1
+kind: Config
+clusters:
+- name: next
+  cluster:
+    server: https://127.0.0.1:1
+contexts:
+- name: next
+  context:
+    cluster: next
+    user: next
+users:
+- name: next
+  user:
+    token: abc
+current-context: next
+`), 0o600)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	stopped := false
+	old := &cluster{name: "old", clientset: fake.NewSimpleClientset(), breaker: &breaker{}}
+	b := &bot{
+		clusters:   &clusters{current: "old", byName: map[string]*cluster{"old": old}, stopInformers: func() { stopped = true }},
+		cache:      newReplyCache(time.Minute),
+		apiTimeout: time.Second,
+	}
+	b.cache.set("get pods", "from the old cluster")
+
+	start := time.Now()
+	ctx := withClusters(context.Background(), b.clusters)
+	if _, err := b.run(ctx, "U1", reloadConfigCommand{}, "", map[string]string{"path": kubeconfig}); err != nil {
+		t.Fatal(err)
+	}
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Errorf("reload took %s, want the informer sync bounded by API_TIMEOUT", elapsed)
+	}
+	if !stopped {
+		t.Error("the old clusters' informers weren't stopped")
+	}
+	if _, ok := b.cache.get("get pods"); ok {
+		t.Error("replies cached from the old clusters are still served")
+	}
+	if names := b.clusters.names(); len(names) != 1 || names[0] != "next" {
+		t.Errorf("clusters = %v, want the reloaded kubeconfig's", names)
+	}
+	b.clusters.stopInformers()
+}
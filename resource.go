@@ -0,0 +1,150 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/kubernetes"
+)
+
+// resourceCommand lists or gets any resource with the dynamic client, for configured commands without a built-in
+// handler
+type resourceCommand struct {
+	regexpCommand
+	resource schema.GroupVersionResource
+	verb     string
+	columns  []columnDefinition
+}
+
+func (c resourceCommand) Handle(ctx context.Context, args map[string]string, _ kubernetes.Interface) (string, error) {
+	cl := clusterFrom(ctx)
+	if cl == nil || cl.dynamic == nil {
+		return "", errors.New("no dynamic client for this cluster")
+	}
+	client := cl.dynamic.Resource(c.resource).Namespace(args["namespace"])
+
+	var items []unstructured.Unstructured
+	if c.verb == "get" {
+		obj, err := client.Get(ctx, args["name"], metav1.GetOptions{})
+		if err != nil {
+			return "", c.wrapError(fmt.Sprintf("failed to get %s `%s`", c.resource.Resource, args["name"]), err)
+		}
+		items = append(items, *obj)
+	} else {
+		listOptions, err := listOptionsFromArgs(args)
+		if err != nil {
+			return "", err
+		}
+		list, err := client.List(ctx, listOptions)
+		if err != nil {
+			return "", c.wrapError(fmt.Sprintf("failed to list %s in %s", c.resource.Resource, namespaceScope(args["namespace"])), err)
+		}
+		items = list.Items
+	}
+	if len(items) == 0 {
+		if args["namespace"] == "" {
+			return "No resources found", nil
+		}
+		return "No resources found in " + args["namespace"], nil
+	}
+
+	header := []string{"NAME"}
+	for _, column := range c.columns {
+		header = append(header, column.Name)
+	}
+	header = append(header, "AGE")
+	if args["allNamespaces"] != "" {
+		header = append([]string{"NAMESPACE"}, header...)
+	}
+	rows := make([][]string, 0, len(items))
+	for _, item := range items {
+		row := []string{item.GetName()}
+		for _, column := range c.columns {
+			row = append(row, fieldValue(item, column.Path))
+		}
+		row = append(row, age(item.GetCreationTimestamp()))
+		if args["allNamespaces"] != "" {
+			row = append([]string{item.GetNamespace()}, row...)
+		}
+		rows = append(rows, row)
+	}
+
+	return renderSortedTable(args, header, rows)
+}
+
+// wrapError adds context to err, pointing out when the resource isn't served at all, e.g. a CRD that isn't
+// installed
+func (c resourceCommand) wrapError(context string, err error) error {
+	if apierrors.IsNotFound(err) && c.verb == "list" {
+		return fmt.Errorf("%s, is %s installed in this cluster? %w", context, c.resource.GroupResource(), err)
+	}
+
+	return fmt.Errorf("%s: %w", context, err)
+}
+
+// fieldValue renders the field of obj at the dotted path, e.g. status.phase, or <none> if it isn't set
+func fieldValue(obj unstructured.Unstructured, path string) string {
+	value, ok, err := unstructured.NestedFieldNoCopy(obj.Object, strings.Split(strings.TrimPrefix(path, "."), ".")...)
+	if !ok || err != nil || value == nil {
+		return "<none>"
+	}
+
+	return fmt.Sprint(value)
+}
+
+// getResourceCommand lists any resource the cluster serves, e.g. a CRD's, resolving its name through discovery
+// the way kubectl get does
+type getResourceCommand struct {
+	regexpCommand
+}
+
+func (c getResourceCommand) Handle(ctx context.Context, args map[string]string, clientset kubernetes.Interface) (string, error) {
+	cl := clusterFrom(ctx)
+	if cl == nil || cl.mapper == nil {
+		return "", errors.New("no discovery for this cluster")
+	}
+
+	// Like kubectl, the name may be qualified by its group, e.g. certificates.cert-manager.io
+	resource, err := cl.mapper.ResourceFor(schema.ParseGroupResource(args["resource"]).WithVersion(""))
+	if meta.IsNoMatchError(err) {
+		return fmt.Sprintf("the cluster doesn't have a resource type `%s`", args["resource"]), nil
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to resolve resource `%s`: %w", args["resource"], err)
+	}
+	kind, err := cl.mapper.KindFor(resource)
+	if err != nil {
+		return "", fmt.Errorf("failed to resolve resource `%s`: %w", args["resource"], err)
+	}
+	mapping, err := cl.mapper.RESTMapping(kind.GroupKind(), kind.Version)
+	if err != nil {
+		return "", fmt.Errorf("failed to resolve resource `%s`: %w", args["resource"], err)
+	}
+
+	if mapping.Scope.Name() != meta.RESTScopeNameNamespace {
+		// Cluster-scoped resources have no namespace to filter by, whatever was asked for
+		args = withoutNamespace(args)
+	} else if args["namespace"] == "" && args["allNamespaces"] == "" {
+		return fmt.Sprintf("%s are namespaced, retry with `-n $namespace` or `-A`", resource.Resource), nil
+	}
+
+	return resourceCommand{regexpCommand: c.regexpCommand, resource: resource, verb: "list"}.Handle(ctx, args, clientset)
+}
+
+// withoutNamespace returns a copy of args listing a cluster-scoped resource
+func withoutNamespace(args map[string]string) map[string]string {
+	copied := make(map[string]string, len(args))
+	for k, v := range args {
+		copied[k] = v
+	}
+	copied["namespace"], copied["allNamespaces"] = "", ""
+
+	return copied
+}
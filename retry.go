@@ -0,0 +1,49 @@
+package main
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	utilnet "k8s.io/apimachinery/pkg/util/net"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/util/retry"
+)
+
+// apiBackoff is how read-only commands retry transient Kubernetes API errors, giving up after about 1.5s of
+// waiting so a struggling API server still gets a reply out quickly
+var apiBackoff = wait.Backoff{
+	Steps:    4,
+	Duration: 200 * time.Millisecond,
+	Factor:   2,
+	Jitter:   0.1,
+	Cap:      time.Second,
+}
+
+// transient reports whether err is worth retrying. Errors like 403 and 404 will come back the same every time.
+func transient(ctx context.Context, err error) bool {
+	// Once ctx is done every retry would fail the same way
+	if ctx.Err() != nil {
+		return false
+	}
+
+	return apierrors.IsTooManyRequests(err) ||
+		apierrors.IsServerTimeout(err) ||
+		utilnet.IsConnectionReset(err) ||
+		utilnet.IsConnectionRefused(err) ||
+		utilnet.IsProbableEOF(err)
+}
+
+// withRetry runs fn, running it again with apiBackoff for as long as it fails with a transient error
+func withRetry(ctx context.Context, name string, fn func() error) error {
+	attempt := 0
+	return retry.OnError(apiBackoff, func(err error) bool {
+		if !transient(ctx, err) {
+			return false
+		}
+		attempt++
+		slog.Debug("retrying transient Kubernetes API error", "command", name, "attempt", attempt, "err", err)
+		return true
+	}, fn)
+}
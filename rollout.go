@@ -0,0 +1,143 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"time"
+
+	appsv1 "k8s.io/api/apps/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes"
+)
+
+// rolloutRestartCommand rolls every pod of a Deployment, i.e. kubectl rollout restart deployment
+type rolloutRestartCommand struct {
+	mutatingCommand
+}
+
+func (rolloutRestartCommand) Handle(ctx context.Context, args map[string]string, clientset kubernetes.Interface) (string, error) {
+	// Same as kubectl: changing a pod template annotation makes the Deployment roll out new pods
+	patch := fmt.Sprintf(`{"spec":{"template":{"metadata":{"annotations":{"kubectl.kubernetes.io/restartedAt":%q}}}}}`, time.Now().Format(time.RFC3339))
+	_, err := clientset.AppsV1().Deployments(args["namespace"]).Patch(ctx, args["name"], types.StrategicMergePatchType, []byte(patch), metav1.PatchOptions{})
+	if err != nil {
+		return "", fmt.Errorf("failed to restart deployment `%s` in `%s`: %w", args["name"], args["namespace"], err)
+	}
+
+	return fmt.Sprintf("deployment.apps/%s restarted", args["name"]), nil
+}
+
+// rolloutStatusCommand reports whether a Deployment has finished rolling out, i.e. kubectl rollout status deployment
+type rolloutStatusCommand struct {
+	regexpCommand
+}
+
+func (rolloutStatusCommand) Handle(ctx context.Context, args map[string]string, clientset kubernetes.Interface) (string, error) {
+	deployment, err := clientset.AppsV1().Deployments(args["namespace"]).Get(ctx, args["name"], metav1.GetOptions{})
+	if err != nil {
+		return "", fmt.Errorf("failed to get deployment `%s` in `%s`: %w", args["name"], args["namespace"], err)
+	}
+
+	return rolloutStatus(deployment), nil
+}
+
+// rolloutStatus mirrors the messages kubectl rollout status prints for a single snapshot of a Deployment
+func rolloutStatus(d *appsv1.Deployment) string {
+	if d.Generation > d.Status.ObservedGeneration {
+		return "Waiting for deployment spec update to be observed..."
+	}
+
+	for _, condition := range d.Status.Conditions {
+		if condition.Type == appsv1.DeploymentProgressing && condition.Reason == "ProgressDeadlineExceeded" {
+			return fmt.Sprintf("deployment %q exceeded its progress deadline", d.Name)
+		}
+	}
+
+	if d.Spec.Replicas != nil && d.Status.UpdatedReplicas < *d.Spec.Replicas {
+		return fmt.Sprintf("Waiting for deployment %q rollout to finish: %d out of %d new replicas have been updated...", d.Name, d.Status.UpdatedReplicas, *d.Spec.Replicas)
+	}
+	if d.Status.Replicas > d.Status.UpdatedReplicas {
+		return fmt.Sprintf("Waiting for deployment %q rollout to finish: %d old replicas are pending termination...", d.Name, d.Status.Replicas-d.Status.UpdatedReplicas)
+	}
+	if d.Status.AvailableReplicas < d.Status.UpdatedReplicas {
+		return fmt.Sprintf("Waiting for deployment %q rollout to finish: %d of %d updated replicas are available...", d.Name, d.Status.AvailableReplicas, d.Status.UpdatedReplicas)
+	}
+
+	return fmt.Sprintf("deployment %q successfully rolled out", d.Name)
+}
+
+// rolloutHistoryCommand lists a Deployment's revisions and the images each ran, i.e. kubectl rollout history
+// deployment
+type rolloutHistoryCommand struct {
+	regexpCommand
+}
+
+func (rolloutHistoryCommand) Handle(ctx context.Context, args map[string]string, clientset kubernetes.Interface) (string, error) {
+	deployment, err := clientset.AppsV1().Deployments(args["namespace"]).Get(ctx, args["name"], metav1.GetOptions{})
+	if err != nil {
+		return "", fmt.Errorf("failed to get deployment `%s` in `%s`: %w", args["name"], args["namespace"], err)
+	}
+	selector, err := metav1.LabelSelectorAsSelector(deployment.Spec.Selector)
+	if err != nil {
+		return "", fmt.Errorf("deployment `%s` has an invalid selector: %w", args["name"], err)
+	}
+	list, err := clientset.AppsV1().ReplicaSets(args["namespace"]).List(ctx, metav1.ListOptions{LabelSelector: selector.String()})
+	if err != nil {
+		return "", fmt.Errorf("failed to list replicasets of deployment `%s` in `%s`: %w", args["name"], args["namespace"], err)
+	}
+
+	// The selector can match replicasets the deployment doesn't own, e.g. another deployment's with the same labels
+	var owned []appsv1.ReplicaSet
+	for _, rs := range list.Items {
+		if owner := metav1.GetControllerOf(&rs); owner != nil && owner.UID == deployment.UID {
+			owned = append(owned, rs)
+		}
+	}
+	if len(owned) == 0 {
+		return fmt.Sprintf("deployment `%s` has no revisions yet", args["name"]), nil
+	}
+	sort.Slice(owned, func(i, j int) bool {
+		return replicaSetRevision(owned[i]) > replicaSetRevision(owned[j])
+	})
+
+	rows := make([][]string, 0, len(owned))
+	for _, rs := range owned {
+		rows = append(rows, []string{rs.Annotations[revisionAnnotation], rs.Name, containerImages(rs.Spec.Template.Spec), age(rs.CreationTimestamp)})
+	}
+
+	return renderTable([]string{"REVISION", "REPLICASET", "IMAGES", "AGE"}, rows), nil
+}
+
+// revisionAnnotation is where the deployment controller records which revision of a deployment a replicaset is
+const revisionAnnotation = "deployment.kubernetes.io/revision"
+
+// replicaSetRevision is the revision rs was created for, or 0 if it doesn't say
+func replicaSetRevision(rs appsv1.ReplicaSet) int64 {
+	revision, _ := strconv.ParseInt(rs.Annotations[revisionAnnotation], 10, 64)
+	return revision
+}
+
+// imageCommand lists the images a Deployment's pod template runs, a quicker answer than describing it
+type imageCommand struct {
+	regexpCommand
+}
+
+func (imageCommand) Handle(ctx context.Context, args map[string]string, clientset kubernetes.Interface) (string, error) {
+	deployment, err := clientset.AppsV1().Deployments(args["namespace"]).Get(ctx, args["name"], metav1.GetOptions{})
+	if err != nil {
+		return "", fmt.Errorf("failed to get deployment `%s` in `%s`: %w", args["name"], args["namespace"], err)
+	}
+
+	spec := deployment.Spec.Template.Spec
+	rows := make([][]string, 0, len(spec.InitContainers)+len(spec.Containers))
+	for _, container := range spec.InitContainers {
+		rows = append(rows, []string{container.Name, "init", container.Image})
+	}
+	for _, container := range spec.Containers {
+		rows = append(rows, []string{container.Name, "app", container.Image})
+	}
+
+	return renderTable([]string{"CONTAINER", "TYPE", "IMAGE"}, rows), nil
+}
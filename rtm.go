@@ -0,0 +1,102 @@
+package main
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"github.com/slack-go/slack"
+)
+
+// reconnectWarning is how long after the RTM connection drops we start complaining that it hasn't come back
+const reconnectWarning = time.Minute
+
+// runRTM serves b over Slack's RTM websocket API until ctx is cancelled
+func runRTM(ctx context.Context, b *bot) {
+	rtm := b.api.NewRTM()
+	go rtm.ManageConnection()
+
+	// ManageConnection reconnects on its own, this only checks that it actually did
+	reconnected := time.NewTimer(reconnectWarning)
+	reconnected.Stop()
+	defer reconnected.Stop()
+
+	for {
+		var msg slack.RTMEvent
+		select {
+		case <-ctx.Done():
+			slog.Info("shutting down, disconnecting from Slack")
+			if err := rtm.Disconnect(); err != nil {
+				slog.Error("failed to disconnect from Slack", "err", err)
+			}
+			return
+		case <-reconnected.C:
+			slog.Error("still not reconnected to Slack", "since", reconnectWarning)
+			reconnected.Reset(reconnectWarning)
+			continue
+		case msg = <-rtm.IncomingEvents:
+		}
+
+		//fmt.Print("Event Received: %s\n, msg.Data")
+		switch ev := msg.Data.(type) {
+		case *slack.HelloEvent:
+			// Ignore hello
+
+		case *slack.ConnectingEvent:
+			slog.Info("connecting to Slack", "attempt", ev.Attempt, "connections", ev.ConnectionCount)
+
+		case *slack.ConnectedEvent:
+			b.ready.Store(true)
+			reconnected.Stop()
+			if ev.ConnectionCount > 1 {
+				slackReconnectsTotal.Inc()
+				slog.Info("reconnected to Slack", "connections", ev.ConnectionCount)
+			}
+
+		case *slack.DisconnectedEvent:
+			b.ready.Store(false)
+			if ev.Intentional {
+				slog.Info("disconnected from Slack")
+				return
+			}
+			slog.Warn("disconnected from Slack, reconnecting", "cause", ev.Cause)
+			reconnected.Reset(reconnectWarning)
+
+		case *slack.MessageEvent:
+			b.dispatch(ctx, rtmMessage(ev))
+
+		case *slack.PresenceChangeEvent:
+			slog.Debug("presence change", "user", ev.User, "presence", ev.Presence)
+
+		case *slack.LatencyReport:
+			slog.Debug("current latency", "latency", ev.Value)
+			b.latency.Store(int64(ev.Value))
+
+		case *slack.DesktopNotificationEvent:
+			slog.Debug("desktop notification", "channel", ev.Channel, "title", ev.Title)
+
+		case *slack.RTMError:
+			slog.Error("RTM error", "err", ev.Error())
+
+		case *slack.InvalidAuthEvent:
+			slog.Error("invalid Slack credentials")
+			return
+
+		default:
+
+			// Ignore other events..
+			// fmt.Printf("Unexpected: %v\n", msg.Data)
+		}
+	}
+}
+
+// rtmMessage returns the message an RTM message event carries
+func rtmMessage(ev *slack.MessageEvent) message {
+	return message{
+		channel:         ev.Channel,
+		user:            ev.User,
+		text:            ev.Msg.Text,
+		timestamp:       ev.Msg.Timestamp,
+		threadTimestamp: ev.Msg.ThreadTimestamp,
+	}
+}
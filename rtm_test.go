@@ -0,0 +1,29 @@
+package main
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/slack-go/slack"
+)
+
+// threadedMessage is the message the RTM and Socket Mode events in these tests carry
+var threadedMessage = message{
+	channel:         "C1",
+	user:            "U1",
+	text:            "<@UBOT> k get po",
+	timestamp:       "1700000000.000200",
+	threadTimestamp: "1700000000.000100",
+}
+
+func TestRTMMessage(t *testing.T) {
+	var ev slack.MessageEvent
+	payload := `{"type":"message","channel":"C1","user":"U1","text":"<@UBOT> k get po","ts":"1700000000.000200","thread_ts":"1700000000.000100"}`
+	if err := json.Unmarshal([]byte(payload), &ev); err != nil {
+		t.Fatal(err)
+	}
+
+	if got := rtmMessage(&ev); got != threadedMessage {
+		t.Errorf("rtmMessage = %+v, want %+v", got, threadedMessage)
+	}
+}
@@ -0,0 +1,41 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"strconv"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+// scaleCommand changes the replica count of a Deployment, i.e. kubectl scale deployment
+type scaleCommand struct {
+	mutatingCommand
+}
+
+func (scaleCommand) Handle(ctx context.Context, args map[string]string, clientset kubernetes.Interface) (string, error) {
+	if args["replicas"] == "" {
+		return "", fmt.Errorf("`--replicas` is required to scale deployment `%s`", args["name"])
+	}
+	// Parsed as 32 bits so huge counts are refused rather than wrapping around to a small one
+	replicas, err := strconv.ParseInt(args["replicas"], 10, 32)
+	if err != nil || replicas < 0 {
+		return "", fmt.Errorf("`--replicas` must be between 0 and %d, got `%s`", math.MaxInt32, args["replicas"])
+	}
+
+	deploymentsClient := clientset.AppsV1().Deployments(args["namespace"])
+	scale, err := deploymentsClient.GetScale(ctx, args["name"], metav1.GetOptions{})
+	if err != nil {
+		return "", fmt.Errorf("failed to get deployment `%s` in `%s`: %w", args["name"], args["namespace"], err)
+	}
+
+	oldReplicas := scale.Spec.Replicas
+	scale.Spec.Replicas = int32(replicas)
+	if _, err := deploymentsClient.UpdateScale(ctx, args["name"], scale, metav1.UpdateOptions{}); err != nil {
+		return "", fmt.Errorf("failed to scale deployment `%s` in `%s`: %w", args["name"], args["namespace"], err)
+	}
+
+	return fmt.Sprintf("deployment.apps/%s scaled from %d to %d replicas", args["name"], oldReplicas, replicas), nil
+}
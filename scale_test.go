@@ -0,0 +1,19 @@
+package main
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func TestScaleRejectsOutOfRangeReplicas(t *testing.T) {
+	for _, replicas := range []string{"4294967297", "2147483648", "-1", "two"} {
+		args := map[string]string{"name": "web", "namespace": "team-a", "replicas": replicas}
+		_, err := scaleCommand{}.Handle(context.Background(), args, fake.NewSimpleClientset())
+		if err == nil || !strings.Contains(err.Error(), "must be between 0 and 2147483647") {
+			t.Errorf("--replicas=%s: err = %v, want it refused", replicas, err)
+		}
+	}
+}
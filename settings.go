@@ -0,0 +1,129 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// settingEnvVars maps each setting CONFIG_FILE may hold to the env var it stands in for. Lists are joined with
+// commas, the way the env vars take them.
+var settingEnvVars = map[string]string{
+	"slackTokens":        "SLACK_TOKEN",
+	"slackAppTokens":     "SLACK_APP_TOKEN",
+	"slackSigningSecret": "SLACK_SIGNING_SECRET",
+	"slackDebug":         "SLACK_DEBUG",
+	"kubeconfig":         "KUBECONFIG",
+	"commandsConfig":     "COMMANDS_CONFIG",
+	"commandPrefixes":    "COMMAND_PREFIX",
+	"impersonation":      "IMPERSONATION_CONFIG",
+	"allowedChannels":    "ALLOWED_CHANNELS",
+	"allowedUsers":       "ALLOWED_USERS",
+	"adminUsers":         "ADMIN_USERS",
+	"allowedNamespaces":  "ALLOWED_NAMESPACES",
+	"deniedNamespaces":   "DENIED_NAMESPACES",
+	"defaultNamespace":   "DEFAULT_NAMESPACE",
+	"execAllowedCmds":    "EXEC_ALLOWED_CMDS",
+	"readOnly":           "READ_ONLY",
+	"apiTimeout":         "API_TIMEOUT",
+	"cacheTTL":           "CACHE_TTL",
+	"rateLimit":          "RATE_LIMIT",
+	"rateBurst":          "RATE_BURST",
+	"watchDuration":      "WATCH_DURATION",
+	"watchInterval":      "WATCH_INTERVAL",
+	"workers":            "WORKERS",
+	"auditLog":           "AUDIT_LOG",
+	"auditWebhook":       "AUDIT_WEBHOOK",
+	"redisURL":           "REDIS_URL",
+	"healthPort":         "HEALTH_PORT",
+	"httpPort":           "HTTP_PORT",
+	"logLevel":           "LOG_LEVEL",
+	"outputFormat":       "OUTPUT_FORMAT",
+	"largeReplies":       "LARGE_REPLIES",
+	"threadReplies":      "THREAD_REPLIES",
+	"statusEmoji":        "STATUS_EMOJI",
+	"unknownReply":       "UNKNOWN_REPLY",
+	"quietOnUnknown":     "QUIET_ON_UNKNOWN",
+}
+
+// settingEnvRefRegexp matches a setting's ${NAME} references to env vars. Only the braced form is expanded, so
+// values with a bare $ in them, e.g. a password, are left alone.
+var settingEnvRefRegexp = regexp.MustCompile(`\$\{([A-Za-z_][A-Za-z0-9_]*)\}`)
+
+// loadSettings sets the env vars for the settings in the YAML file at path, e.g. allowedUsers: [U123, U456], so
+// everything configured by env can be kept in one file instead. Values may refer to env vars as ${NAME} to keep
+// secrets out of the file, quoted inside [...] lists, and env vars that are already set win over the file.
+func loadSettings(path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("failed to read config file %q: %w", path, err)
+	}
+	values, err := parseSettings(data)
+	if err != nil {
+		return fmt.Errorf("invalid config file %q: %w", path, err)
+	}
+
+	for name, value := range values {
+		if _, ok := os.LookupEnv(name); ok {
+			continue
+		}
+		if err := os.Setenv(name, value); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// parseSettings returns the env var values of the settings in data, expanding the env vars they refer to and
+// referring to the line at fault in errors
+func parseSettings(data []byte) (map[string]string, error) {
+	var settings map[string]yaml.Node
+	if err := yaml.Unmarshal(data, &settings); err != nil {
+		return nil, err
+	}
+
+	values := make(map[string]string, len(settings))
+	for key, node := range settings {
+		name, ok := settingEnvVars[key]
+		if !ok {
+			return nil, fmt.Errorf("line %d: unknown setting %q", node.Line, key)
+		}
+
+		var items []string
+		switch node.Kind {
+		case yaml.ScalarNode:
+			items = []string{node.Value}
+		case yaml.SequenceNode:
+			for _, item := range node.Content {
+				if item.Kind != yaml.ScalarNode {
+					return nil, fmt.Errorf("line %d: %s must be a list of values", item.Line, key)
+				}
+				items = append(items, item.Value)
+			}
+		default:
+			return nil, fmt.Errorf("line %d: %s must be a value or a list of them", node.Line, key)
+		}
+
+		for i, item := range items {
+			var missing []string
+			items[i] = settingEnvRefRegexp.ReplaceAllStringFunc(item, func(ref string) string {
+				env := settingEnvRefRegexp.FindStringSubmatch(ref)[1]
+				value, ok := os.LookupEnv(env)
+				if !ok {
+					missing = append(missing, env)
+				}
+				return value
+			})
+			if len(missing) > 0 {
+				return nil, fmt.Errorf("line %d: %s refers to %s, which isn't set", node.Line, key, strings.Join(missing, ", "))
+			}
+		}
+		values[name] = strings.Join(items, ",")
+	}
+
+	return values, nil
+}
@@ -0,0 +1,29 @@
+package main
+
+import "testing"
+
+func TestParseSettingsExpandsBracedEnvVars(t *testing.T) {
+	t.Setenv("MIBOT_TEST_TOKEN", "xoxb-123")
+
+	values, err := parseSettings([]byte(`
+slackTokens: ["${MIBOT_TEST_TOKEN}", "xoxb-$456"]
+slackSigningSecret: pa$$word$HOME
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for name, want := range map[string]string{
+		"SLACK_TOKEN":          "xoxb-123,xoxb-$456",
+		"SLACK_SIGNING_SECRET": "pa$$word$HOME",
+	} {
+		if got := values[name]; got != want {
+			t.Errorf("%s = %q, want %q", name, got, want)
+		}
+	}
+}
+
+func TestParseSettingsRejectsUnsetEnvVars(t *testing.T) {
+	if _, err := parseSettings([]byte("slackTokens: ${MIBOT_TEST_UNSET}\n")); err == nil {
+		t.Error("parseSettings accepted a reference to an env var that isn't set")
+	}
+}
@@ -0,0 +1,164 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/slack-go/slack"
+)
+
+// startSlackHTTPServer serves Slack's HTTP callbacks, i.e. slash commands and interactivity, on addr until ctx is cancelled. Every
+// request must be signed with signingSecret.
+func startSlackHTTPServer(ctx context.Context, addr string, bots workspaces, signingSecret string) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/slack/commands", func(w http.ResponseWriter, r *http.Request) {
+		if !verifySlackRequest(w, r, signingSecret) {
+			return
+		}
+		s, err := slack.SlashCommandParse(r)
+		if err != nil {
+			http.Error(w, "malformed slash command", http.StatusBadRequest)
+			return
+		}
+
+		b := bots.forTeam(s.TeamID)
+		if b == nil {
+			http.Error(w, "unknown workspace", http.StatusNotFound)
+			return
+		}
+
+		// Slack only waits 3s for a response, so acknowledge now and reply through the response URL
+		go b.handleSlashCommand(ctx, s)
+		w.WriteHeader(http.StatusOK)
+	})
+	mux.HandleFunc("/slack/interactivity", func(w http.ResponseWriter, r *http.Request) {
+		if !verifySlackRequest(w, r, signingSecret) {
+			return
+		}
+		var callback slack.InteractionCallback
+		if err := json.Unmarshal([]byte(r.FormValue("payload")), &callback); err != nil {
+			http.Error(w, "malformed interaction payload", http.StatusBadRequest)
+			return
+		}
+
+		b := bots.forTeam(callback.Team.ID)
+		if b == nil {
+			http.Error(w, "unknown workspace", http.StatusNotFound)
+			return
+		}
+
+		go b.handleInteraction(ctx, callback)
+		w.WriteHeader(http.StatusOK)
+	})
+
+	server := &http.Server{Addr: addr, Handler: mux, ReadHeaderTimeout: 10 * time.Second}
+	go func() {
+		if err := server.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			slog.Error("Slack HTTP server stopped", "addr", addr, "err", err)
+		}
+	}()
+	go func() {
+		<-ctx.Done()
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		server.Shutdown(shutdownCtx)
+	}()
+}
+
+// verifySlackRequest checks r was signed by Slack with signingSecret, replying with an error if not. It leaves
+// r's body ready to be read again.
+func verifySlackRequest(w http.ResponseWriter, r *http.Request, signingSecret string) bool {
+	verifier, err := slack.NewSecretsVerifier(r.Header, signingSecret)
+	if err != nil {
+		http.Error(w, "missing or stale Slack signature", http.StatusUnauthorized)
+		return false
+	}
+	body, err := io.ReadAll(io.TeeReader(r.Body, &verifier))
+	if err != nil {
+		http.Error(w, "failed to read request", http.StatusBadRequest)
+		return false
+	}
+	if err := verifier.Ensure(); err != nil {
+		slog.Warn("rejecting Slack request with a bad signature", "path", r.URL.Path, "err", err)
+		http.Error(w, "bad Slack signature", http.StatusUnauthorized)
+		return false
+	}
+	r.Body = io.NopCloser(bytes.NewReader(body))
+
+	return true
+}
+
+// handleSlashCommand runs the command in s, e.g. /mibot get pods -n foo, and replies to its response URL
+func (b *bot) handleSlashCommand(ctx context.Context, s slack.SlashCommand) {
+	m := message{channel: s.ChannelID, user: s.UserID, text: s.Text, responseURL: s.ResponseURL}
+	reply := b.refusal(m)
+	if reply == "" {
+		text := slashCommandText(strings.TrimSpace(s.Text))
+		slog.Info("slash command received", "user", m.user, "channel", m.channel, "text", text)
+
+		var err error
+		if reply, err = b.respond(ctx, m, text); err != nil {
+			slog.Error("command failed", "user", m.user, "channel", m.channel, "err", err)
+			reply = fmt.Sprintf("⚠️ %v", err)
+		}
+	}
+	// Blocks post their own replies
+	if reply == "" {
+		return
+	}
+
+	b.replyToSlashCommand(ctx, m, reply)
+}
+
+// slashResponseLimit is how many replies Slack accepts on a slash command's response URL
+const slashResponseLimit = 5
+
+// tooLongNotice ends slash command replies that had to be cut short
+const tooLongNotice = "\nthe reply is too long to show in full here, narrow it down with `grep` or `-n`, or mention mibot in a channel it's in"
+
+// replyToSlashCommand sends reply to m's response URL. Like send, large replies are split over several messages
+// when LARGE_REPLIES is paginate, but they can't be uploaded as a snippet to a channel mibot may not be in. Those,
+// and replies needing more messages than the response URL takes, are cut short and only shown to the user.
+func (b *bot) replyToSlashCommand(ctx context.Context, m message, reply string) {
+	var replies []*slack.WebhookMessage
+	if pages := paginate(reply, messageLimit); len(reply) <= snippetThreshold {
+		replies = append(replies, &slack.WebhookMessage{ResponseType: slack.ResponseTypeInChannel, Text: reply})
+	} else if b.paginate && len(pages) <= slashResponseLimit {
+		for _, page := range pages {
+			replies = append(replies, &slack.WebhookMessage{ResponseType: slack.ResponseTypeInChannel, Text: page})
+		}
+	} else {
+		first := paginate(reply, messageLimit-len(tooLongNotice))[0]
+		replies = append(replies, &slack.WebhookMessage{ResponseType: slack.ResponseTypeEphemeral, Text: first + tooLongNotice})
+	}
+
+	for _, r := range replies {
+		if err := slack.PostWebhookContext(ctx, m.responseURL, r); err != nil {
+			slog.Error("failed to reply to slash command", "channel", m.channel, "err", err)
+			return
+		}
+	}
+}
+
+// slashCommandText turns the text of a slash command into a command mibot understands. The slash command stands
+// in for the command prefix, so /mibot get pods -n foo runs kubectl get pods -n foo.
+func slashCommandText(text string) string {
+	rest, watch := extractWatch(text)
+	prefixed := commandPrefixes[0] + " " + rest
+	if withoutContext, _ := extractContext(prefixed); findCommand(withoutContext) != nil {
+		rest = prefixed
+	}
+	if watch {
+		return watchPrefix + rest
+	}
+
+	return rest
+}
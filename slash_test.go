@@ -0,0 +1,97 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/slack-go/slack"
+)
+
+func TestSlashCommandsAvoidPostingToTheChannel(t *testing.T) {
+	b := &bot{
+		clusters:      &clusters{byName: map[string]*cluster{}},
+		confirmations: newConfirmations(newMemoryStore()),
+		auth:          authorizer{users: map[string]bool{"U1": true}},
+	}
+	// Slash commands work in channels mibot isn't in, so nothing may be posted there directly
+	m := message{channel: "C1", user: "U1", responseURL: "https://hooks.slack.com/commands/T1/1/abc"}
+
+	for text, want := range map[string]string{
+		"k logs -f web -n team-a":   "streamed commands can't run as a slash command",
+		"k delete po web -n team-a": "run the slash command again with `confirm`",
+	} {
+		reply, err := b.respond(context.Background(), m, slashCommandText(strings.TrimPrefix(text, "k ")))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !strings.Contains(reply, want) {
+			t.Errorf("reply to %s = %q, want it to contain %q", text, reply, want)
+		}
+	}
+}
+
+func TestReplyToSlashCommandFitsResponseURL(t *testing.T) {
+	var mu sync.Mutex
+	var posted []slack.WebhookMessage
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var reply slack.WebhookMessage
+		if err := json.NewDecoder(r.Body).Decode(&reply); err != nil {
+			t.Error(err)
+		}
+		mu.Lock()
+		posted = append(posted, reply)
+		mu.Unlock()
+	}))
+	defer server.Close()
+
+	var lines []string
+	for i := 0; i < 300; i++ {
+		lines = append(lines, fmt.Sprintf("web-%03d   1/1   Running   0   5m", i))
+	}
+	large := "```\n" + strings.Join(lines, "\n") + "\n```"
+	m := message{channel: "C1", user: "U1", responseURL: server.URL}
+
+	for _, tc := range []struct {
+		name      string
+		paginate  bool
+		reply     string
+		want      int
+		ephemeral bool
+		lastPage  string
+	}{
+		{"small", false, "pod/web deleted", 1, false, ""},
+		{"paginated", true, large, 3, false, "web-299"},
+		{"snippet", false, large, 1, true, ""},
+		{"too many pages", true, strings.Repeat(large+"\n", 3), 1, true, ""},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			posted = nil
+			b := &bot{paginate: tc.paginate}
+			b.replyToSlashCommand(context.Background(), m, tc.reply)
+
+			if len(posted) != tc.want {
+				t.Fatalf("posted %d replies, want %d", len(posted), tc.want)
+			}
+			for _, reply := range posted {
+				if len(reply.Text) > messageLimit {
+					t.Errorf("reply is %d long, over the %d limit", len(reply.Text), messageLimit)
+				}
+				if ephemeral := reply.ResponseType == slack.ResponseTypeEphemeral; ephemeral != tc.ephemeral {
+					t.Errorf("ephemeral = %t, want %t", ephemeral, tc.ephemeral)
+				}
+				if tc.ephemeral && !strings.HasSuffix(reply.Text, tooLongNotice) {
+					t.Errorf("reply %q doesn't say it was cut short", reply.Text)
+				}
+			}
+			if tc.lastPage != "" && !strings.Contains(posted[len(posted)-1].Text, tc.lastPage) {
+				t.Errorf("the last page is missing %s", tc.lastPage)
+			}
+		})
+	}
+}
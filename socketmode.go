@@ -0,0 +1,85 @@
+package main
+
+import (
+	"context"
+	"log/slog"
+
+	"github.com/slack-go/slack"
+	"github.com/slack-go/slack/slackevents"
+	"github.com/slack-go/slack/socketmode"
+)
+
+// runSocketMode serves b over Slack's Socket Mode API, which needs an app-level token on b.api, until ctx is
+// cancelled
+func runSocketMode(ctx context.Context, b *bot) {
+	client := socketmode.New(b.api)
+	go func() {
+		if err := client.RunContext(ctx); err != nil && ctx.Err() == nil {
+			slog.Error("Socket Mode connection stopped", "err", err)
+		}
+	}()
+
+	for {
+		var evt socketmode.Event
+		select {
+		case <-ctx.Done():
+			slog.Info("shutting down, disconnecting from Slack")
+			return
+		case evt = <-client.Events:
+		}
+
+		switch evt.Type {
+		case socketmode.EventTypeConnecting:
+			slog.Info("connecting to Slack with Socket Mode")
+
+		case socketmode.EventTypeConnectionError:
+			b.ready.Store(false)
+			slog.Error("Socket Mode connection failed", "err", evt.Data)
+
+		case socketmode.EventTypeConnected:
+			b.ready.Store(true)
+
+		case socketmode.EventTypeInvalidAuth:
+			slog.Error("invalid Slack credentials")
+			return
+
+		case socketmode.EventTypeEventsAPI:
+			eventsAPIEvent, ok := evt.Data.(slackevents.EventsAPIEvent)
+			if !ok {
+				continue
+			}
+			client.Ack(*evt.Request)
+
+			switch ev := eventsAPIEvent.InnerEvent.Data.(type) {
+			case *slackevents.MessageEvent:
+				b.dispatch(ctx, socketModeMessage(ev))
+			case *slackevents.AppHomeOpenedEvent:
+				if ev.Tab == "home" {
+					go b.publishHome(ctx, ev.User)
+				}
+			}
+
+		case socketmode.EventTypeInteractive:
+			callback, ok := evt.Data.(slack.InteractionCallback)
+			if !ok {
+				continue
+			}
+			client.Ack(*evt.Request)
+			go b.handleInteraction(ctx, callback)
+
+		default:
+			// Ignore other events..
+		}
+	}
+}
+
+// socketModeMessage returns the message an Events API message event carries
+func socketModeMessage(ev *slackevents.MessageEvent) message {
+	return message{
+		channel:         ev.Channel,
+		user:            ev.User,
+		text:            ev.Text,
+		timestamp:       ev.TimeStamp,
+		threadTimestamp: ev.ThreadTimeStamp,
+	}
+}
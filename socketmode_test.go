@@ -0,0 +1,25 @@
+package main
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/slack-go/slack/slackevents"
+)
+
+func TestSocketModeMessage(t *testing.T) {
+	payload := `{"type":"event_callback","team_id":"T1","api_app_id":"A1","event":{"type":"message","channel":"C1","user":"U1",` +
+		`"text":"<@UBOT> k get po","ts":"1700000000.000200","thread_ts":"1700000000.000100","channel_type":"channel"}}`
+	event, err := slackevents.ParseEvent(json.RawMessage(payload), slackevents.OptionNoVerifyToken())
+	if err != nil {
+		t.Fatal(err)
+	}
+	ev, ok := event.InnerEvent.Data.(*slackevents.MessageEvent)
+	if !ok {
+		t.Fatalf("parsed %T, want a message event", event.InnerEvent.Data)
+	}
+
+	if got := socketModeMessage(ev); got != threadedMessage {
+		t.Errorf("socketModeMessage = %+v, want %+v", got, threadedMessage)
+	}
+}
@@ -0,0 +1,140 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// sortColumns maps each --sort-by key to the table column it sorts by
+var sortColumns = map[string]string{
+	"age":      "AGE",
+	"name":     "NAME",
+	"restarts": "RESTARTS",
+	"status":   "STATUS",
+}
+
+// validateSortBy rejects --sort-by keys we don't know how to sort by
+func validateSortBy(sortBy string) error {
+	if _, ok := sortColumns[sortBy]; ok || sortBy == "" {
+		return nil
+	}
+
+	keys := make([]string, 0, len(sortColumns))
+	for key := range sortColumns {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return fmt.Errorf("invalid `--sort-by=%s`, must be one of: %s", sortBy, strings.Join(keys, ", "))
+}
+
+// renderSortedTable renders rows like renderTable, first dropping those whose names don't contain the grep term in
+// args and sorting the rest by the column of the --sort-by flag if there is one. Like kubectl, sorting is always
+// ascending, so sorting by age lists the oldest first.
+func renderSortedTable(args map[string]string, header []string, rows [][]string) (string, error) {
+	// Commands that can tell what's unhealthy or show more columns handle --problems and -o wide before they get here
+	if args["problems"] != "" {
+		return "", errors.New("`--problems` only works with get pods")
+	}
+	if args["wide"] != "" {
+		return "", errors.New("`-o wide` only works with get pods and get deploy")
+	}
+	rows, err := grepRows(args["grep"], header, rows)
+	if err != nil {
+		return "", err
+	}
+	if len(rows) == 0 && args["grep"] != "" {
+		return noMatches(args["grep"]), nil
+	}
+
+	sortBy := args["sortBy"]
+	if sortBy == "" {
+		return renderTable(header, rows), nil
+	}
+
+	column := -1
+	for i, name := range header {
+		if name == sortColumns[sortBy] {
+			column = i
+		}
+	}
+	if column == -1 {
+		return "", fmt.Errorf("can't `--sort-by=%s` here, there's no %s column", sortBy, sortColumns[sortBy])
+	}
+
+	less := func(a, b string) bool { return a < b }
+	switch sortBy {
+	case "age":
+		less = func(a, b string) bool { return parseHumanDuration(a) > parseHumanDuration(b) }
+	case "restarts":
+		less = func(a, b string) bool {
+			x, _ := strconv.Atoi(a)
+			y, _ := strconv.Atoi(b)
+			return x < y
+		}
+	}
+	sort.SliceStable(rows, func(i, j int) bool {
+		return less(rows[i][column], rows[j][column])
+	})
+
+	return renderTable(header, rows), nil
+}
+
+// grepRows keeps the rows whose name contains term, ignoring case. Events have no NAME column, so they're matched
+// by their OBJECT instead.
+func grepRows(term string, header []string, rows [][]string) ([][]string, error) {
+	if term == "" {
+		return rows, nil
+	}
+
+	column := -1
+	for i, name := range header {
+		if name == "NAME" || name == "OBJECT" && column == -1 {
+			column = i
+		}
+	}
+	if column == -1 {
+		return nil, fmt.Errorf("can't `grep %s` here, there's no NAME column", term)
+	}
+
+	matched := make([][]string, 0, len(rows))
+	for _, row := range rows {
+		if nameContains(row[column], term) {
+			matched = append(matched, row)
+		}
+	}
+	return matched, nil
+}
+
+// nameContains reports whether name contains term, ignoring case
+func nameContains(name, term string) bool {
+	return strings.Contains(strings.ToLower(name), strings.ToLower(term))
+}
+
+// noMatches is the reply when grep filtered out everything
+func noMatches(term string) string {
+	return fmt.Sprintf("no matches for `%s`", term)
+}
+
+// parseHumanDuration reverses humanDuration, e.g. 5h12m, treating anything it can't parse as no time at all
+func parseHumanDuration(s string) time.Duration {
+	units := map[byte]time.Duration{'s': time.Second, 'm': time.Minute, 'h': time.Hour, 'd': 24 * time.Hour, 'y': 365 * 24 * time.Hour}
+
+	var d time.Duration
+	n := 0
+	for i := 0; i < len(s); i++ {
+		if c := s[i]; c >= '0' && c <= '9' {
+			n = n*10 + int(c-'0')
+		} else if unit, ok := units[c]; ok {
+			d += time.Duration(n) * unit
+			n = 0
+		} else {
+			return 0
+		}
+	}
+
+	return d
+}
@@ -0,0 +1,85 @@
+package main
+
+import (
+	"context"
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+// getPVCCommand lists PersistentVolumeClaims, i.e. kubectl get pvc
+type getPVCCommand struct {
+	regexpCommand
+}
+
+func (getPVCCommand) Handle(ctx context.Context, args map[string]string, clientset kubernetes.Interface) (string, error) {
+	listOptions, err := listOptionsFromArgs(args)
+	if err != nil {
+		return "", err
+	}
+	list, err := clientset.CoreV1().PersistentVolumeClaims(args["namespace"]).List(ctx, listOptions)
+	if err != nil {
+		return "", fmt.Errorf("failed to list persistentvolumeclaims in `%s`: %w", args["namespace"], err)
+	}
+	if len(list.Items) == 0 {
+		return "No resources found in " + args["namespace"], nil
+	}
+
+	rows := make([][]string, 0, len(list.Items))
+	for _, pvc := range list.Items {
+		// A claim only has a capacity once it is bound to a volume
+		rows = append(rows, []string{pvc.Name, string(pvc.Status.Phase), pvc.Spec.VolumeName, storageCapacity(pvc.Status.Capacity), storageClass(pvc.Spec.StorageClassName)})
+	}
+
+	return renderSortedTable(args, []string{"NAME", "STATUS", "VOLUME", "CAPACITY", "STORAGECLASS"}, rows)
+}
+
+// getPVCommand lists PersistentVolumes, i.e. kubectl get pv
+type getPVCommand struct {
+	regexpCommand
+}
+
+func (getPVCommand) Handle(ctx context.Context, args map[string]string, clientset kubernetes.Interface) (string, error) {
+	listOptions, err := listOptionsFromArgs(args)
+	if err != nil {
+		return "", err
+	}
+	list, err := clientset.CoreV1().PersistentVolumes().List(ctx, listOptions)
+	if err != nil {
+		return "", fmt.Errorf("failed to list persistentvolumes: %w", err)
+	}
+	if len(list.Items) == 0 {
+		return "No resources found", nil
+	}
+
+	rows := make([][]string, 0, len(list.Items))
+	for _, pv := range list.Items {
+		claim := ""
+		if ref := pv.Spec.ClaimRef; ref != nil {
+			claim = ref.Namespace + "/" + ref.Name
+		}
+		rows = append(rows, []string{pv.Name, string(pv.Status.Phase), claim, storageCapacity(pv.Spec.Capacity), storageClass(&pv.Spec.StorageClassName)})
+	}
+
+	return renderSortedTable(args, []string{"NAME", "STATUS", "CLAIM", "CAPACITY", "STORAGECLASS"}, rows)
+}
+
+// storageCapacity renders the storage in a PVC or PV's resources, e.g. 10Gi
+func storageCapacity(resources corev1.ResourceList) string {
+	storage, ok := resources[corev1.ResourceStorage]
+	if !ok {
+		return ""
+	}
+
+	return storage.String()
+}
+
+// storageClass renders a storage class name, which is optional
+func storageClass(name *string) string {
+	if name == nil || *name == "" {
+		return "<none>"
+	}
+
+	return *name
+}
@@ -0,0 +1,95 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"golang.org/x/time/rate"
+)
+
+// storeTimeout bounds each call to the store, so a slow Redis can't hold up every command
+const storeTimeout = 2 * time.Second
+
+// Store holds the state that should survive the bot reconnecting or restarting, such as pending confirmations and
+// how many commands each user has run recently
+type Store interface {
+	// Put stores value under key until ttl has passed, replacing any value already there
+	Put(ctx context.Context, key string, value []byte, ttl time.Duration) error
+	// Take removes and returns the value under key, reporting false if there isn't one or it has expired
+	Take(ctx context.Context, key string) ([]byte, bool, error)
+	// Allow reports whether key may do something now under a token bucket filling at limit with room for burst,
+	// using up one of its tokens if so
+	Allow(ctx context.Context, key string, limit rate.Limit, burst int) (bool, error)
+}
+
+// newStore returns a Redis store for redisURL, or the in-memory store when it's empty
+func newStore(redisURL string) (Store, error) {
+	if redisURL == "" {
+		return newMemoryStore(), nil
+	}
+
+	store, err := newRedisStore(redisURL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid REDIS_URL: %w", err)
+	}
+	return store, nil
+}
+
+// memoryStore is the default Store, which forgets everything when mibot restarts
+type memoryStore struct {
+	mu       sync.Mutex
+	values   map[string]storedValue
+	limiters map[string]*rate.Limiter
+}
+
+type storedValue struct {
+	value   []byte
+	expires time.Time
+}
+
+func newMemoryStore() *memoryStore {
+	return &memoryStore{values: make(map[string]storedValue), limiters: make(map[string]*rate.Limiter)}
+}
+
+// Put stores value under key, dropping any other values that have expired
+func (s *memoryStore) Put(_ context.Context, key string, value []byte, ttl time.Duration) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	for k, v := range s.values {
+		if now.After(v.expires) {
+			delete(s.values, k)
+		}
+	}
+	s.values[key] = storedValue{value: value, expires: now.Add(ttl)}
+
+	return nil
+}
+
+func (s *memoryStore) Take(_ context.Context, key string) ([]byte, bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	v, ok := s.values[key]
+	delete(s.values, key)
+	if !ok || time.Now().After(v.expires) {
+		return nil, false, nil
+	}
+
+	return v.value, true, nil
+}
+
+func (s *memoryStore) Allow(_ context.Context, key string, limit rate.Limit, burst int) (bool, error) {
+	s.mu.Lock()
+	limiter, ok := s.limiters[key]
+	if !ok {
+		limiter = rate.NewLimiter(limit, burst)
+		s.limiters[key] = limiter
+	}
+	s.mu.Unlock()
+
+	return limiter.Allow(), nil
+}
@@ -0,0 +1,142 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/slack-go/slack"
+	"k8s.io/client-go/kubernetes"
+)
+
+const (
+	// defaultStreamDuration is how long a streamed reply keeps updating before it stops by itself
+	defaultStreamDuration = 2 * time.Minute
+	// defaultStreamInterval is how often a streamed reply is edited with whatever changed since the last edit
+	defaultStreamInterval = 3 * time.Second
+)
+
+// streamingCommand is implemented by commands that can keep their reply up to date rather than replying once,
+// e.g. kubectl logs -f
+type streamingCommand interface {
+	// Streams reports whether args ask for a streamed reply
+	Streams(args map[string]string) bool
+	// Stream runs the command until ctx is done, calling update with the whole reply each time it changes
+	Stream(ctx context.Context, args map[string]string, clientset kubernetes.Interface, update func(reply string)) error
+}
+
+// streams holds the running stream of each user in each channel so they can be stopped early
+type streams struct {
+	mu      sync.Mutex
+	running map[string]*context.CancelFunc
+}
+
+func newStreams() *streams {
+	return &streams{running: make(map[string]*context.CancelFunc)}
+}
+
+// start returns a context for a new stream for user in channel that ends after duration, stopping any stream
+// they already had running there. done must be called once the stream has finished.
+func (s *streams) start(ctx context.Context, user, channel string, duration time.Duration) (context.Context, func()) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	key := user + "/" + channel
+	if cancel, ok := s.running[key]; ok {
+		(*cancel)()
+	}
+	ctx, cancel := context.WithTimeout(ctx, duration)
+	handle := &cancel
+	s.running[key] = handle
+
+	return ctx, func() {
+		cancel()
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		// A newer stream may have replaced this one already
+		if s.running[key] == handle {
+			delete(s.running, key)
+		}
+	}
+}
+
+// stop ends the running stream of user in channel, reporting whether there was one
+func (s *streams) stop(user, channel string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	key := user + "/" + channel
+	cancel, ok := s.running[key]
+	if ok {
+		(*cancel)()
+		delete(s.running, key)
+	}
+
+	return ok
+}
+
+// stream posts a reply to m and keeps editing it with the output of cmd until the stream times out or the user
+// says stop. It returns once the first reply is posted, leaving the stream running in the background.
+func (b *bot) stream(ctx context.Context, m message, cmd Command, kubeContext string, args map[string]string) error {
+	cl, _, err := b.clusterFor(m.user, kubeContext)
+	if err != nil {
+		return err
+	}
+	ts, err := b.post(m, fmt.Sprintf("streaming for up to %s, say `stop` to end it early", b.streamDuration))
+	if err != nil {
+		return err
+	}
+
+	streamCtx, done := b.streams.start(ctx, m.user, m.channel, b.streamDuration)
+	go func() {
+		defer done()
+
+		var mu sync.Mutex
+		var latest string
+		changed := false
+		update := func(reply string) {
+			mu.Lock()
+			defer mu.Unlock()
+			latest, changed = reply, true
+		}
+		flush := func(footer string) {
+			mu.Lock()
+			reply, ok := latest, changed
+			changed = false
+			mu.Unlock()
+			if !ok && footer == "" {
+				return
+			}
+			if _, _, _, err := b.api.UpdateMessage(m.channel, ts, slack.MsgOptionText(reply+footer, false)); err != nil {
+				slog.Error("failed to update streamed reply", "channel", m.channel, "err", err)
+			}
+		}
+
+		errs := make(chan error, 1)
+		go func() {
+			errs <- cmd.(streamingCommand).Stream(withCluster(streamCtx, cl), args, cl.clientset, update)
+		}()
+
+		ticker := time.NewTicker(b.streamInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				flush("")
+			case err := <-errs:
+				recordCommand(cmd, m.channel, err)
+				if err != nil {
+					slog.Error("stream failed", "user", m.user, "channel", m.channel, "err", err)
+					flush(fmt.Sprintf("\n⚠️ %v", err))
+					return
+				}
+				flush("\n_stopped_")
+				return
+			}
+		}
+	}()
+
+	return nil
+}
@@ -0,0 +1,93 @@
+package main
+
+import "strings"
+
+// suggestCommand returns the command text closest to text when text looks like a typo of a registered command,
+// e.g. k get deploy -n foo for k get deployemnts -n foo, or "" if nothing is close
+func suggestCommand(text string) string {
+	words := strings.Fields(text)
+	if len(words) == 0 {
+		return ""
+	}
+
+	var suggestion string
+	best := -1
+	for _, cmd := range commands {
+		verb := commandWords(cmd.Usage())
+		if len(verb) == 0 || len(words) < len(verb) {
+			continue
+		}
+		distance, ok := commandDistance(words[:len(verb)], verb)
+		if !ok || (best != -1 && distance >= best) {
+			continue
+		}
+
+		// Keep the user's spelling of kubectl and their arguments, fixing only the command itself
+		candidate := append(append([]string{words[0]}, verb[1:]...), words[len(verb):]...)
+		if !isCommandPrefix(words[0]) {
+			candidate = append(append([]string{}, verb...), words[len(verb):]...)
+		}
+		best, suggestion = distance, strings.Join(candidate, " ")
+		if !cmd.Matches(suggestion) {
+			suggestion = cmd.Usage()
+		}
+	}
+
+	return suggestion
+}
+
+// commandDistance sums the edit distance between each typed word and the command word in the same position,
+// reporting whether every word is close enough to be a typo. Any command prefix, e.g. k or kubectl, counts as an
+// exact match for the first word.
+func commandDistance(typed, verb []string) (int, bool) {
+	total := 0
+	for i, word := range typed {
+		if i == 0 && isCommandPrefix(word) {
+			word = verb[0]
+		}
+		distance := levenshtein(word, verb[i])
+		if distance > max(1, len(word)/2) {
+			return 0, false
+		}
+		total += distance
+	}
+
+	return total, true
+}
+
+// commandWords returns the leading words of usage that name the command, before any flags or placeholders
+func commandWords(usage string) []string {
+	var words []string
+	for _, word := range strings.Fields(usage) {
+		if strings.ContainsAny(word[:1], "-$[") {
+			break
+		}
+		words = append(words, word)
+	}
+
+	return words
+}
+
+// levenshtein returns the number of single character edits needed to turn a into b
+func levenshtein(a, b string) int {
+	ra, rb := []rune(a), []rune(b)
+	previous := make([]int, len(rb)+1)
+	current := make([]int, len(rb)+1)
+	for j := range previous {
+		previous[j] = j
+	}
+
+	for i := 1; i <= len(ra); i++ {
+		current[0] = i
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			current[j] = min(previous[j]+1, current[j-1]+1, previous[j-1]+cost)
+		}
+		previous, current = current, previous
+	}
+
+	return previous[len(rb)]
+}
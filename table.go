@@ -0,0 +1,62 @@
+package main
+
+import (
+	"strings"
+	"text/tabwriter"
+)
+
+// renderTable lines up rows under header as columns and wraps the result in a code block for Slack
+func renderTable(header []string, rows [][]string) string {
+	var b strings.Builder
+	b.WriteString("```\n")
+
+	w := tabwriter.NewWriter(&b, 0, 0, 3, ' ', 0)
+	w.Write([]byte(strings.Join(header, "\t") + "\n"))
+	for _, row := range rows {
+		w.Write([]byte(strings.Join(row, "\t") + "\n"))
+	}
+	w.Flush()
+
+	b.WriteString("```")
+	return b.String()
+}
+
+// parseTable recovers the header and rows of a reply rendered by renderTable, reporting false if reply is anything
+// else. Cells are cut at the offsets of the header's columns, which tabwriter starts every line's cells at.
+func parseTable(reply string) ([]string, [][]string, bool) {
+	body, ok := strings.CutPrefix(reply, "```\n")
+	if !ok || !strings.HasSuffix(body, "```") || strings.Count(body, "```") != 1 {
+		return nil, nil, false
+	}
+	lines := strings.Split(strings.TrimSuffix(body, "\n```"), "\n")
+
+	// Header cells never contain more than one space in a row, and tabwriter pads columns with at least three
+	headerLine := []rune(lines[0])
+	var offsets []int
+	for i := range headerLine {
+		if headerLine[i] != ' ' && (i == 0 || i >= 2 && headerLine[i-1] == ' ' && headerLine[i-2] == ' ') {
+			offsets = append(offsets, i)
+		}
+	}
+
+	cells := func(line string) []string {
+		runes := []rune(line)
+		row := make([]string, len(offsets))
+		for i, start := range offsets {
+			end := len(runes)
+			if i+1 < len(offsets) {
+				end = offsets[i+1]
+			}
+			if start < len(runes) {
+				row[i] = strings.TrimSpace(string(runes[start:min(end, len(runes))]))
+			}
+		}
+		return row
+	}
+
+	rows := make([][]string, 0, len(lines)-1)
+	for _, line := range lines[1:] {
+		rows = append(rows, cells(line))
+	}
+	return cells(lines[0]), rows, true
+}
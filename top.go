@@ -0,0 +1,157 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/client-go/kubernetes"
+	metricsv1beta1 "k8s.io/metrics/pkg/apis/metrics/v1beta1"
+	metricsclientset "k8s.io/metrics/pkg/client/clientset/versioned"
+)
+
+// errMetricsUnavailable is returned when the cluster has no metrics.k8s.io API, i.e. metrics-server isn't installed
+var errMetricsUnavailable = errors.New("metrics-server not available in this cluster")
+
+// topPodsCommand shows pod CPU and memory usage from metrics-server, i.e. kubectl top pods
+type topPodsCommand struct {
+	regexpCommand
+}
+
+func (topPodsCommand) Handle(ctx context.Context, args map[string]string, clientset kubernetes.Interface) (string, error) {
+	metrics, err := metricsFor(ctx, clientset)
+	if errors.Is(err, errMetricsUnavailable) {
+		return err.Error(), nil
+	} else if err != nil {
+		return "", err
+	}
+	listOptions, err := listOptionsFromArgs(args)
+	if err != nil {
+		return "", err
+	}
+	list, err := metrics.MetricsV1beta1().PodMetricses(args["namespace"]).List(ctx, listOptions)
+	if err != nil {
+		return "", fmt.Errorf("failed to get pod metrics in `%s`: %w", args["namespace"], err)
+	}
+	if len(list.Items) == 0 {
+		return "No resources found in " + args["namespace"], nil
+	}
+
+	type podUsage struct {
+		name          string
+		milliCPU      int64
+		memoryMiBytes int64
+	}
+	usages := make([]podUsage, 0, len(list.Items))
+	for _, pm := range list.Items {
+		usage := podUsage{name: pm.Name}
+		for _, c := range pm.Containers {
+			usage.milliCPU += c.Usage.Cpu().MilliValue()
+			usage.memoryMiBytes += c.Usage.Memory().Value() / (1024 * 1024)
+		}
+		usages = append(usages, usage)
+	}
+	sort.SliceStable(usages, func(i, j int) bool {
+		return usages[i].milliCPU > usages[j].milliCPU
+	})
+
+	rows := make([][]string, 0, len(usages))
+	for _, usage := range usages {
+		rows = append(rows, []string{usage.name, strconv.FormatInt(usage.milliCPU, 10) + "m", strconv.FormatInt(usage.memoryMiBytes, 10) + "Mi"})
+	}
+
+	return renderSortedTable(args, []string{"NAME", "CPU(cores)", "MEMORY(bytes)"}, rows)
+}
+
+// topNodesCommand shows node CPU and memory usage from metrics-server against what's allocatable, i.e. kubectl top nodes
+type topNodesCommand struct {
+	regexpCommand
+}
+
+func (topNodesCommand) Handle(ctx context.Context, args map[string]string, clientset kubernetes.Interface) (string, error) {
+	metrics, err := metricsFor(ctx, clientset)
+	if errors.Is(err, errMetricsUnavailable) {
+		return err.Error(), nil
+	} else if err != nil {
+		return "", err
+	}
+	listOptions, err := listOptionsFromArgs(args)
+	if err != nil {
+		return "", err
+	}
+	list, err := metrics.MetricsV1beta1().NodeMetricses().List(ctx, listOptions)
+	if err != nil {
+		return "", fmt.Errorf("failed to get node metrics: %w", err)
+	}
+	nodes, err := clientset.CoreV1().Nodes().List(ctx, listOptions)
+	if err != nil {
+		return "", fmt.Errorf("failed to list nodes: %w", err)
+	}
+	allocatable := make(map[string]corev1.ResourceList, len(nodes.Items))
+	for _, node := range nodes.Items {
+		allocatable[node.Name] = node.Status.Allocatable
+	}
+
+	type nodeUsage struct {
+		name          string
+		milliCPU      int64
+		cpuPercent    int64
+		memoryMiBytes int64
+		memoryPercent int64
+	}
+	usages := make([]nodeUsage, 0, len(list.Items))
+	for _, nm := range list.Items {
+		usage := nodeUsage{
+			name:          nm.Name,
+			milliCPU:      nm.Usage.Cpu().MilliValue(),
+			memoryMiBytes: nm.Usage.Memory().Value() / (1024 * 1024),
+		}
+		if a, ok := allocatable[nm.Name]; ok {
+			if cpu := a.Cpu().MilliValue(); cpu > 0 {
+				usage.cpuPercent = nm.Usage.Cpu().MilliValue() * 100 / cpu
+			}
+			if memory := a.Memory().Value(); memory > 0 {
+				usage.memoryPercent = nm.Usage.Memory().Value() * 100 / memory
+			}
+		}
+		usages = append(usages, usage)
+	}
+	sort.SliceStable(usages, func(i, j int) bool {
+		return usages[i].cpuPercent > usages[j].cpuPercent
+	})
+
+	rows := make([][]string, 0, len(usages))
+	for _, usage := range usages {
+		rows = append(rows, []string{
+			usage.name,
+			strconv.FormatInt(usage.milliCPU, 10) + "m",
+			strconv.FormatInt(usage.cpuPercent, 10) + "%",
+			strconv.FormatInt(usage.memoryMiBytes, 10) + "Mi",
+			strconv.FormatInt(usage.memoryPercent, 10) + "%",
+		})
+	}
+
+	return renderSortedTable(args, []string{"NAME", "CPU(cores)", "CPU%", "MEMORY(bytes)", "MEMORY%"}, rows)
+}
+
+// metricsFor returns the metrics clientset for the cluster in ctx, or errMetricsUnavailable if the cluster
+// doesn't serve the metrics API
+func metricsFor(ctx context.Context, clientset kubernetes.Interface) (metricsclientset.Interface, error) {
+	cl := clusterFrom(ctx)
+	if cl == nil || cl.metrics == nil {
+		return nil, errMetricsUnavailable
+	}
+
+	if _, err := clientset.Discovery().ServerResourcesForGroupVersion(metricsv1beta1.SchemeGroupVersion.String()); err != nil {
+		if apierrors.IsNotFound(err) {
+			return nil, errMetricsUnavailable
+		}
+		return nil, fmt.Errorf("failed to discover the metrics API: %w", err)
+	}
+
+	return cl.metrics, nil
+}
@@ -0,0 +1,30 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"runtime"
+
+	"k8s.io/client-go/kubernetes"
+)
+
+// version is mibot's build version, set with -ldflags "-X main.version=..."
+var version = "dev"
+
+// versionCommand reports the versions of mibot and the cluster it talks to
+type versionCommand struct {
+	regexpCommand
+}
+
+func (versionCommand) Handle(ctx context.Context, args map[string]string, clientset kubernetes.Interface) (string, error) {
+	serverVersion, err := clientset.Discovery().ServerVersion()
+	if err != nil {
+		return "", fmt.Errorf("failed to get server version: %w", err)
+	}
+
+	return renderTable([]string{"COMPONENT", "VERSION"}, [][]string{
+		{"mibot", version},
+		{"go", runtime.Version()},
+		{"kubernetes", serverVersion.GitVersion},
+	}), nil
+}
@@ -0,0 +1,68 @@
+package main
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"k8s.io/client-go/kubernetes"
+)
+
+// watchPrefix asks for a read-only command's reply to be kept up to date, like watch kubectl get pods
+const watchPrefix = "watch "
+
+// watchCommand streams a read-only command by rerunning it every interval
+type watchCommand struct {
+	Command
+	interval time.Duration
+	// refresh runs the command each interval, see bot.refresh
+	refresh func(ctx context.Context, cmd Command, args map[string]string, clientset kubernetes.Interface) (string, error)
+}
+
+func (watchCommand) Streams(map[string]string) bool {
+	return true
+}
+
+func (c watchCommand) Stream(ctx context.Context, args map[string]string, clientset kubernetes.Interface, update func(string)) error {
+	ticker := time.NewTicker(c.interval)
+	defer ticker.Stop()
+
+	for {
+		reply, err := c.refresh(ctx, c.Command, args, clientset)
+		if err != nil {
+			// Running out of time part way through a refresh is how watching normally stops
+			if ctx.Err() != nil {
+				return nil
+			}
+			return err
+		}
+		update(reply + "\n_last updated " + time.Now().Format("15:04:05 MST") + "_")
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
+
+// refresh runs one of a watched command's refreshes against the cluster carried by ctx like run would, bounded by
+// API_TIMEOUT, retried and counted by the cluster's breaker, but never served from the cache
+func (b *bot) refresh(ctx context.Context, cmd Command, args map[string]string, clientset kubernetes.Interface) (string, error) {
+	if cl := clusterFrom(ctx); cl != nil && cl.breaker != nil {
+		if err := b.checkBreaker(ctx, cl); err != nil {
+			return "", err
+		}
+	}
+
+	return b.handle(ctx, cmd, args, clientset)
+}
+
+// extractWatch removes a leading watch from text, reporting whether there was one
+func extractWatch(text string) (string, bool) {
+	if !strings.HasPrefix(text, watchPrefix) {
+		return text, false
+	}
+
+	return strings.TrimSpace(strings.TrimPrefix(text, watchPrefix)), true
+}
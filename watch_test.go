@@ -0,0 +1,41 @@
+package main
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+// hangingCommand is a read-only command whose API call never returns until it's given up on
+type hangingCommand struct {
+	regexpCommand
+}
+
+func (hangingCommand) Handle(ctx context.Context, _ map[string]string, _ kubernetes.Interface) (string, error) {
+	<-ctx.Done()
+	return "", ctx.Err()
+}
+
+func TestWatchRefreshHonoursAPITimeout(t *testing.T) {
+	cl := &cluster{name: "prod", clientset: fake.NewSimpleClientset(), breaker: &breaker{}}
+	b := &bot{apiTimeout: 50 * time.Millisecond}
+	watch := watchCommand{Command: hangingCommand{}, interval: time.Second, refresh: b.refresh}
+
+	ctx, cancel := context.WithTimeout(withCluster(context.Background(), cl), time.Minute)
+	defer cancel()
+	start := time.Now()
+	err := watch.Stream(ctx, map[string]string{}, cl.clientset, func(string) {})
+	if err == nil || !strings.Contains(err.Error(), "timed out") {
+		t.Errorf("Stream = %v, want the refresh to time out", err)
+	}
+	if elapsed := time.Since(start); elapsed > 10*time.Second {
+		t.Errorf("hung refresh held the watch for %s", elapsed)
+	}
+	if cl.breaker.failures != 1 {
+		t.Errorf("breaker counted %d failures, want the timed out refresh", cl.breaker.failures)
+	}
+}
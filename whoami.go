@@ -0,0 +1,113 @@
+package main
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
+)
+
+// selfSubjectReviewVersions are the versions of authentication.k8s.io to ask for our own identity, newest first.
+// SelfSubjectReview went beta in Kubernetes 1.27 and GA in 1.28.
+var selfSubjectReviewVersions = []string{"v1", "v1beta1", "v1alpha1"}
+
+// whoamiCommand reports which cluster mibot is talking to, who it is there and where it may go, i.e. kubectl auth
+// whoami
+type whoamiCommand struct {
+	regexpCommand
+}
+
+func (whoamiCommand) Handle(ctx context.Context, args map[string]string, clientset kubernetes.Interface) (string, error) {
+	cl := clusterFrom(ctx)
+	if cl == nil {
+		return "", fmt.Errorf("no cluster to report on")
+	}
+
+	kubeContext := cl.name
+	if kubeContext == "" {
+		kubeContext = "<in-cluster>"
+	}
+	username, groups := selfSubjectReview(ctx, cl)
+	if username == "" {
+		username = configIdentity(cl.config)
+	}
+	policy, _ := ctx.Value(authorizerKey{}).(authorizer)
+
+	var b strings.Builder
+	b.WriteString("Context:\t" + kubeContext + "\n")
+	b.WriteString("Server:\t" + cl.config.Host + "\n")
+	b.WriteString("User:\t" + username + "\n")
+	if len(groups) > 0 {
+		b.WriteString("Groups:\t" + strings.Join(groups, ", ") + "\n")
+	}
+	b.WriteString("Namespaces:\t" + policy.namespacePolicy() + "\n")
+
+	return "```\n" + b.String() + "```", nil
+}
+
+// selfSubjectReview asks the API server who cl authenticates as, returning an empty username when no version of
+// SelfSubjectReview is served
+func selfSubjectReview(ctx context.Context, cl *cluster) (string, []string) {
+	for _, version := range selfSubjectReviewVersions {
+		review := &unstructured.Unstructured{Object: map[string]interface{}{
+			"apiVersion": "authentication.k8s.io/" + version,
+			"kind":       "SelfSubjectReview",
+		}}
+		resource := schema.GroupVersionResource{Group: "authentication.k8s.io", Version: version, Resource: "selfsubjectreviews"}
+		result, err := cl.dynamic.Resource(resource).Create(ctx, review, metav1.CreateOptions{})
+		if err != nil {
+			continue
+		}
+
+		username, _, _ := unstructured.NestedString(result.Object, "status", "userInfo", "username")
+		groups, _, _ := unstructured.NestedStringSlice(result.Object, "status", "userInfo", "groups")
+		if username != "" {
+			return username, groups
+		}
+	}
+
+	return "", nil
+}
+
+// configIdentity works out who config authenticates as without asking the API server, from who it impersonates,
+// its basic auth username or the subject of its service account token
+func configIdentity(config *rest.Config) string {
+	switch {
+	case config.Impersonate.UserName != "":
+		return config.Impersonate.UserName
+	case config.Username != "":
+		return config.Username
+	}
+
+	token := config.BearerToken
+	if token == "" && config.BearerTokenFile != "" {
+		if data, err := os.ReadFile(config.BearerTokenFile); err == nil {
+			token = strings.TrimSpace(string(data))
+		}
+	}
+	// Service account tokens are JWTs naming the service account as their subject
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return "<unknown>"
+	}
+	payload, err := base64.RawURLEncoding.DecodeString(parts[1])
+	if err != nil {
+		return "<unknown>"
+	}
+	var claims struct {
+		Subject string `json:"sub"`
+	}
+	if err := json.Unmarshal(payload, &claims); err != nil || claims.Subject == "" {
+		return "<unknown>"
+	}
+
+	return claims.Subject
+}
@@ -0,0 +1,46 @@
+package main
+
+import (
+	"context"
+	"hash/fnv"
+	"log/slog"
+)
+
+// workerQueueSize is how many messages can wait for each worker before the transport blocks on it
+const workerQueueSize = 16
+
+// startWorkers starts n workers to handle messages in parallel until ctx is cancelled. Each user's messages in a
+// channel always go to the same worker, so they are answered in the order they were sent.
+func (b *bot) startWorkers(ctx context.Context, n int) {
+	b.workers = make([]chan message, n)
+	for i := range b.workers {
+		queue := make(chan message, workerQueueSize)
+		b.workers[i] = queue
+		go func() {
+			for {
+				select {
+				case <-ctx.Done():
+					return
+				case m := <-queue:
+					b.handleMessage(ctx, m)
+				}
+			}
+		}()
+	}
+}
+
+// dispatch queues m for the worker responsible for its user and channel, dropping it if Slack already delivered it
+func (b *bot) dispatch(ctx context.Context, m message) {
+	if !b.seen.firstSighting(m) {
+		slog.Debug("ignoring redelivered message", "channel", m.channel, "timestamp", m.timestamp)
+		return
+	}
+
+	h := fnv.New32a()
+	h.Write([]byte(m.channel + "/" + m.user))
+
+	select {
+	case <-ctx.Done():
+	case b.workers[h.Sum32()%uint32(len(b.workers))] <- m:
+	}
+}
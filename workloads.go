@@ -0,0 +1,92 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+
+	"k8s.io/client-go/kubernetes"
+)
+
+// getStatefulSetCommand lists StatefulSets, i.e. kubectl get sts
+type getStatefulSetCommand struct {
+	regexpCommand
+}
+
+func (getStatefulSetCommand) Handle(ctx context.Context, args map[string]string, clientset kubernetes.Interface) (string, error) {
+	listOptions, err := listOptionsFromArgs(args)
+	if err != nil {
+		return "", err
+	}
+	list, err := clientset.AppsV1().StatefulSets(args["namespace"]).List(ctx, listOptions)
+	if err != nil {
+		return "", fmt.Errorf("failed to list statefulsets in `%s`: %w", args["namespace"], err)
+	}
+	if len(list.Items) == 0 {
+		return "No resources found in " + args["namespace"], nil
+	}
+
+	rows := make([][]string, 0, len(list.Items))
+	for _, sts := range list.Items {
+		rows = append(rows, []string{sts.Name, readyOf(sts.Status.ReadyReplicas, sts.Status.Replicas), age(sts.CreationTimestamp)})
+	}
+
+	return renderSortedTable(args, []string{"NAME", "READY", "AGE"}, rows)
+}
+
+// getDaemonSetCommand lists DaemonSets, i.e. kubectl get ds
+type getDaemonSetCommand struct {
+	regexpCommand
+}
+
+func (getDaemonSetCommand) Handle(ctx context.Context, args map[string]string, clientset kubernetes.Interface) (string, error) {
+	listOptions, err := listOptionsFromArgs(args)
+	if err != nil {
+		return "", err
+	}
+	list, err := clientset.AppsV1().DaemonSets(args["namespace"]).List(ctx, listOptions)
+	if err != nil {
+		return "", fmt.Errorf("failed to list daemonsets in `%s`: %w", args["namespace"], err)
+	}
+	if len(list.Items) == 0 {
+		return "No resources found in " + args["namespace"], nil
+	}
+
+	rows := make([][]string, 0, len(list.Items))
+	for _, ds := range list.Items {
+		rows = append(rows, []string{ds.Name, readyOf(ds.Status.NumberReady, ds.Status.DesiredNumberScheduled), age(ds.CreationTimestamp)})
+	}
+
+	return renderSortedTable(args, []string{"NAME", "READY", "AGE"}, rows)
+}
+
+// getReplicaSetCommand lists ReplicaSets, i.e. kubectl get rs
+type getReplicaSetCommand struct {
+	regexpCommand
+}
+
+func (getReplicaSetCommand) Handle(ctx context.Context, args map[string]string, clientset kubernetes.Interface) (string, error) {
+	listOptions, err := listOptionsFromArgs(args)
+	if err != nil {
+		return "", err
+	}
+	list, err := clientset.AppsV1().ReplicaSets(args["namespace"]).List(ctx, listOptions)
+	if err != nil {
+		return "", fmt.Errorf("failed to list replicasets in `%s`: %w", args["namespace"], err)
+	}
+	if len(list.Items) == 0 {
+		return "No resources found in " + args["namespace"], nil
+	}
+
+	rows := make([][]string, 0, len(list.Items))
+	for _, rs := range list.Items {
+		rows = append(rows, []string{rs.Name, readyOf(rs.Status.ReadyReplicas, rs.Status.Replicas), age(rs.CreationTimestamp)})
+	}
+
+	return renderSortedTable(args, []string{"NAME", "READY", "AGE"}, rows)
+}
+
+// readyOf renders ready out of desired the way kubectl's READY column does, e.g. 2/3
+func readyOf(ready, desired int32) string {
+	return strconv.Itoa(int(ready)) + "/" + strconv.Itoa(int(desired))
+}
@@ -0,0 +1,63 @@
+package main
+
+import (
+	"log/slog"
+	"sync/atomic"
+
+	"github.com/slack-go/slack"
+)
+
+// workspaces holds a bot for each Slack workspace mibot is connected to, by team ID. The bots share everything
+// but their Slack client, so replies go back through the workspace a message came from.
+type workspaces map[string]*bot
+
+// forTeam returns the bot connected to the workspace with teamID, or nil if we aren't in it
+func (w workspaces) forTeam(teamID string) *bot {
+	b, ok := w[teamID]
+	if !ok {
+		slog.Warn("ignoring request from unknown Slack workspace", "team", teamID)
+	}
+
+	return b
+}
+
+// ready reports whether every workspace's bot is connected
+func (w workspaces) ready() bool {
+	for _, b := range w {
+		if !b.ready.Load() {
+			return false
+		}
+	}
+
+	return true
+}
+
+// forWorkspace returns a copy of b that talks to the workspace api is connected to as userID, with its own
+// connection state and workers
+func (b *bot) forWorkspace(api *slack.Client, userID string) *bot {
+	w := *b
+	w.api = api
+	w.userID = userID
+	w.ready = new(atomic.Bool)
+	w.latency = new(atomic.Int64)
+	w.workers = nil
+
+	return &w
+}
+
+// connect authenticates with Slack using token, and appToken for Socket Mode when it's set, returning a bot for
+// that workspace built from base
+func connect(base *bot, token, appToken string, options []slack.Option) (*bot, string, error) {
+	if appToken != "" {
+		options = append(options, slack.OptionAppLevelToken(appToken))
+	}
+	api := slack.New(token, options...)
+
+	identity, err := api.AuthTest()
+	if err != nil {
+		return nil, "", err
+	}
+
+	b := base.forWorkspace(api, identity.UserID)
+	return b, identity.TeamID, nil
+}